@@ -1,9 +1,21 @@
 package main
 
-import "fio/internal/app"
+import (
+	"fio/internal/app"
+	"fmt"
+	"os"
+)
 
 const configDir = "./configs"
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "reenrich" {
+		if err := app.Reenrich(configDir, os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	app.Run(configDir)
 }
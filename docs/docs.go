@@ -1,5 +1,4 @@
-// Code generated by swaggo/swag. DO NOT EDIT.
-
+// Package docs Code generated by swaggo/swag. DO NOT EDIT
 package docs
 
 import "github.com/swaggo/swag"
@@ -16,8 +15,301 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/api/admin/enrichment-runs": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Enriches the persons matching the filters again, all persons if there are none.\nThe persons are enriched in the background at rate persons a second.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Start Enrichment Run",
+                "operationId": "start-enrichment-run",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 35,
+                        "name": "age",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 65,
+                        "name": "age_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 18,
+                        "name": "age_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "male",
+                        "name": "gender",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "example": [
+                            "male",
+                            "female"
+                        ],
+                        "name": "gender_in",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "exact",
+                            "iexact",
+                            "prefix",
+                            "contains"
+                        ],
+                        "type": "string",
+                        "example": "prefix",
+                        "x-enum-varnames": [
+                            "MatchExact",
+                            "MatchIExact",
+                            "MatchPrefix",
+                            "MatchContains"
+                        ],
+                        "description": "Match selects how name, surname and patronymic are compared, exact by default.",
+                        "name": "match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Vladimir",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "RU",
+                        "name": "nationality",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "example": [
+                            "RU",
+                            "UA"
+                        ],
+                        "name": "nationality_in",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Viktorovych",
+                        "name": "patronymic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "example": true,
+                        "name": "patronymic_is_null",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "dmitriy ushakov",
+                        "description": "Q searches across name, surname and patronymic, results are ranked by relevance.",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Davydov",
+                        "name": "surname",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "example": 5,
+                        "description": "persons enriched a second, the configured rate if omitted",
+                        "name": "rate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "overwrite the fields an operator set",
+                        "name": "force",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/domain.EnrichmentRun"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/enrichment-runs/{runID}": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Reports the progress of the run.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get Enrichment Run",
+                "operationId": "get-enrichment-run",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the run",
+                        "name": "runID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.EnrichmentRun"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/enrichment-runs/{runID}/resume": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Carries on with a stopped run, the persons still queued are spread out from now on at the rate of the run.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Resume Enrichment Run",
+                "operationId": "resume-enrichment-run",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the run",
+                        "name": "runID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/domain.EnrichmentRun"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/api/person": {
             "post": {
+                "description": "The person is saved as pending, age, gender and nationality are filled in later.\nWith keep_attributes the given ones are kept, only the missing ones are filled in.",
                 "consumes": [
                     "application/json"
                 ],
@@ -56,53 +348,390 @@ const docTemplate = `{
                             "$ref": "#/definitions/v1.errorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/person/{personID}": {
+            "get": {
+                "tags": [
+                    "person"
+                ],
+                "summary": "Get Person",
+                "operationId": "get-person",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of person to get",
+                        "name": "personID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Person"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "person"
+                ],
+                "summary": "Replace Person",
+                "operationId": "replace-person",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of person to replace",
+                        "name": "personID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Person content",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.Person"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.statusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "tags": [
+                    "person"
+                ],
+                "summary": "Delete Person",
+                "operationId": "delete-person",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of person to delete",
+                        "name": "personID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.statusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Applies a JSON Merge Patch: omitted fields are left as is, null clears the patronymic",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json"
+                ],
+                "tags": [
+                    "person"
+                ],
+                "summary": "Update Person",
+                "operationId": "update-person",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of person to update",
+                        "name": "personID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update Person content",
+                        "name": "input",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.UpdatePersonInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.statusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/v1.errorResponse"
                         }
                     },
-                    "default": {
-                        "description": "",
-                        "schema": {
-                            "$ref": "#/definitions/v1.errorResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/api/person/{personID}": {
-            "put": {
-                "consumes": [
-                    "application/json"
-                ],
-                "tags": [
-                    "person"
-                ],
-                "summary": "Update Person",
-                "operationId": "update-person",
-                "parameters": [
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/persons": {
+            "get": {
+                "tags": [
+                    "person"
+                ],
+                "summary": "Get Persons",
+                "operationId": "get-persons",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 35,
+                        "name": "age",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 65,
+                        "name": "age_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 18,
+                        "name": "age_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "male",
+                        "name": "gender",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "example": [
+                            "male",
+                            "female"
+                        ],
+                        "name": "gender_in",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "exact",
+                            "iexact",
+                            "prefix",
+                            "contains"
+                        ],
+                        "type": "string",
+                        "example": "prefix",
+                        "x-enum-varnames": [
+                            "MatchExact",
+                            "MatchIExact",
+                            "MatchPrefix",
+                            "MatchContains"
+                        ],
+                        "description": "Match selects how name, surname and patronymic are compared, exact by default.",
+                        "name": "match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Vladimir",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "RU",
+                        "name": "nationality",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "example": [
+                            "RU",
+                            "UA"
+                        ],
+                        "name": "nationality_in",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Viktorovych",
+                        "name": "patronymic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "example": true,
+                        "name": "patronymic_is_null",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "dmitriy ushakov",
+                        "description": "Q searches across name, surname and patronymic, results are ranked by relevance.",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Davydov",
+                        "name": "surname",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            10,
+                            25,
+                            50
+                        ],
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "ID of person to update",
-                        "name": "personID",
-                        "in": "path",
-                        "required": true
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
                     },
                     {
-                        "description": "Update Person content",
-                        "name": "input",
-                        "in": "body",
-                        "schema": {
-                            "$ref": "#/definitions/domain.UpdatePersonInput"
-                        }
+                        "type": "string",
+                        "example": "-age,surname",
+                        "description": "comma separated sort fields, prefix with '-' for descending",
+                        "name": "sort",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/v1.statusResponse"
+                            "$ref": "#/definitions/v1.getPersonsResponse"
                         }
                     },
                     "400": {
@@ -131,26 +760,35 @@ const docTemplate = `{
                     }
                 }
             },
-            "delete": {
+            "post": {
+                "description": "Adds up to 100 persons at once, either all of them or none.\nThe persons are saved as pending, age, gender and nationality are filled in later.\nA person with keep_attributes keeps the ones it was given, only the missing ones are filled in.",
+                "consumes": [
+                    "application/json"
+                ],
                 "tags": [
                     "person"
                 ],
-                "summary": "Delete Person",
-                "operationId": "delete-person",
+                "summary": "Add Persons",
+                "operationId": "add-persons",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID of person to delete",
-                        "name": "personID",
-                        "in": "path",
-                        "required": true
+                        "description": "Persons content",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Person"
+                            }
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/v1.statusResponse"
+                            "$ref": "#/definitions/v1.idsResponse"
                         }
                     },
                     "400": {
@@ -165,6 +803,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/v1.errorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.errorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -180,13 +824,13 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/persons": {
+        "/api/persons/cursor": {
             "get": {
                 "tags": [
                     "person"
                 ],
-                "summary": "Get Persons",
-                "operationId": "get-persons",
+                "summary": "Get Persons By Cursor",
+                "operationId": "get-persons-by-cursor",
                 "parameters": [
                     {
                         "type": "integer",
@@ -194,12 +838,56 @@ const docTemplate = `{
                         "name": "age",
                         "in": "query"
                     },
+                    {
+                        "type": "integer",
+                        "example": 65,
+                        "name": "age_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 18,
+                        "name": "age_min",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "example": "male",
                         "name": "gender",
                         "in": "query"
                     },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "example": [
+                            "male",
+                            "female"
+                        ],
+                        "name": "gender_in",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "exact",
+                            "iexact",
+                            "prefix",
+                            "contains"
+                        ],
+                        "type": "string",
+                        "example": "prefix",
+                        "x-enum-varnames": [
+                            "MatchExact",
+                            "MatchIExact",
+                            "MatchPrefix",
+                            "MatchContains"
+                        ],
+                        "description": "Match selects how name, surname and patronymic are compared, exact by default.",
+                        "name": "match",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "example": "Vladimir",
@@ -212,12 +900,38 @@ const docTemplate = `{
                         "name": "nationality",
                         "in": "query"
                     },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "example": [
+                            "RU",
+                            "UA"
+                        ],
+                        "name": "nationality_in",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "example": "Viktorovych",
                         "name": "patronymic",
                         "in": "query"
                     },
+                    {
+                        "type": "boolean",
+                        "example": true,
+                        "name": "patronymic_is_null",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "dmitriy ushakov",
+                        "description": "Q searches across name, surname and patronymic, results are ranked by relevance.",
+                        "name": "q",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "example": "Davydov",
@@ -236,9 +950,22 @@ const docTemplate = `{
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "page",
-                        "name": "page",
+                        "type": "string",
+                        "description": "cursor to read forward from",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor to read backward from",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "-age,surname",
+                        "description": "comma separated sort fields, prefix with '-' for descending",
+                        "name": "sort",
                         "in": "query"
                     }
                 ],
@@ -246,7 +973,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/v1.getPersonsResponse"
+                            "$ref": "#/definitions/v1.getPersonsCursorResponse"
                         }
                     },
                     "400": {
@@ -278,6 +1005,184 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "domain.Alternative": {
+            "type": "object",
+            "properties": {
+                "probability": {
+                    "type": "number",
+                    "example": 0.12
+                },
+                "value": {
+                    "type": "string",
+                    "example": "UA"
+                }
+            }
+        },
+        "domain.Confidence": {
+            "type": "object",
+            "properties": {
+                "alternatives": {
+                    "description": "Alternatives are the values that were not chosen, the most likely first.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Alternative"
+                    }
+                },
+                "count": {
+                    "description": "Count is the number of samples behind the answer.",
+                    "type": "integer",
+                    "example": 1204
+                },
+                "probability": {
+                    "description": "Probability of the chosen value, nil if the upstream doesn't report one.",
+                    "type": "number",
+                    "example": 0.98
+                },
+                "source": {
+                    "description": "Source names the provider of the answer, e.g. remote or morphology.",
+                    "type": "string",
+                    "example": "remote"
+                }
+            }
+        },
+        "domain.Enrichment": {
+            "type": "object",
+            "properties": {
+                "age": {
+                    "$ref": "#/definitions/domain.Confidence"
+                },
+                "gender": {
+                    "$ref": "#/definitions/domain.Confidence"
+                },
+                "nationality": {
+                    "$ref": "#/definitions/domain.Confidence"
+                }
+            }
+        },
+        "domain.EnrichmentRun": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "done": {
+                    "description": "Done persons are re-enriched, Pending ones are still queued and Failed\nones ran out of attempts.",
+                    "type": "integer",
+                    "example": 700
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "filters": {
+                    "description": "Filters select the persons of the run as query params, empty for all persons.",
+                    "type": "string",
+                    "example": "gender=male\u0026nationality_in=RU"
+                },
+                "force": {
+                    "description": "Force overwrites the fields an operator set.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "pending": {
+                    "type": "integer",
+                    "example": 498
+                },
+                "rate": {
+                    "description": "Rate is the number of persons enriched a second.",
+                    "type": "number",
+                    "example": 5
+                },
+                "total": {
+                    "description": "Total is the number of persons the run has queued.",
+                    "type": "integer",
+                    "example": 1200
+                }
+            }
+        },
+        "domain.EnrichmentStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "complete",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "EnrichmentPending",
+                "EnrichmentComplete",
+                "EnrichmentFailed"
+            ]
+        },
+        "domain.ErrorCode": {
+            "type": "string",
+            "enum": [
+                "internal",
+                "not_found",
+                "conflict",
+                "validation",
+                "unauthorized",
+                "upstream_unavailable",
+                "upstream_failed"
+            ],
+            "x-enum-varnames": [
+                "CodeInternal",
+                "CodeNotFound",
+                "CodeConflict",
+                "CodeValidation",
+                "CodeUnauthorized",
+                "CodeUpstreamUnavailable",
+                "CodeUpstreamFailed"
+            ]
+        },
+        "domain.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "name"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "failed on the 'required' rule"
+                }
+            }
+        },
+        "domain.FieldProvenance": {
+            "type": "object",
+            "properties": {
+                "source": {
+                    "description": "Source is manual or enriched.",
+                    "type": "string",
+                    "enum": [
+                        "manual",
+                        "enriched"
+                    ],
+                    "example": "manual"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.MatchMode": {
+            "type": "string",
+            "enum": [
+                "exact",
+                "iexact",
+                "prefix",
+                "contains"
+            ],
+            "x-enum-varnames": [
+                "MatchExact",
+                "MatchIExact",
+                "MatchPrefix",
+                "MatchContains"
+            ]
+        },
         "domain.Person": {
             "type": "object",
             "required": [
@@ -289,6 +1194,28 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 22
                 },
+                "enrichment": {
+                    "description": "Enrichment is filled in by the service, it is ignored on input.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Enrichment"
+                        }
+                    ]
+                },
+                "enrichment_status": {
+                    "description": "EnrichmentStatus is set by the service, it is ignored on input.",
+                    "enum": [
+                        "pending",
+                        "complete",
+                        "failed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.EnrichmentStatus"
+                        }
+                    ],
+                    "example": "complete"
+                },
                 "gender": {
                     "type": "string",
                     "example": "male"
@@ -297,6 +1224,10 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 1
                 },
+                "keep_attributes": {
+                    "description": "KeepAttributes makes a new person keep the age, gender and nationality\nit was given, only the missing ones are enriched.",
+                    "type": "boolean"
+                },
                 "name": {
                     "type": "string",
                     "example": "Dmitriy"
@@ -308,12 +1239,34 @@ const docTemplate = `{
                     "type": "string",
                     "example": "Vasilevich"
                 },
+                "provenance": {
+                    "description": "Provenance is set by the service, it is ignored on input.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Provenance"
+                        }
+                    ]
+                },
                 "surname": {
                     "type": "string",
                     "example": "Ushakov"
                 }
             }
         },
+        "domain.Provenance": {
+            "type": "object",
+            "properties": {
+                "age": {
+                    "$ref": "#/definitions/domain.FieldProvenance"
+                },
+                "gender": {
+                    "$ref": "#/definitions/domain.FieldProvenance"
+                },
+                "nationality": {
+                    "$ref": "#/definitions/domain.FieldProvenance"
+                }
+            }
+        },
         "domain.UpdatePersonInput": {
             "type": "object",
             "properties": {
@@ -346,7 +1299,59 @@ const docTemplate = `{
         "v1.errorResponse": {
             "type": "object",
             "properties": {
-                "message": {
+                "code": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ErrorCode"
+                        }
+                    ],
+                    "example": "not_found"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "person not found"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FieldError"
+                    }
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not Found"
+                }
+            }
+        },
+        "v1.getPersonsCursorResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Person"
+                    }
+                },
+                "end_cursor": {
+                    "type": "string"
+                },
+                "has_next": {
+                    "type": "boolean"
+                },
+                "has_prev": {
+                    "type": "boolean"
+                },
+                "next": {
+                    "type": "string"
+                },
+                "prev": {
+                    "type": "string"
+                },
+                "start_cursor": {
                     "type": "string"
                 }
             }
@@ -359,6 +1364,41 @@ const docTemplate = `{
                     "items": {
                         "$ref": "#/definitions/domain.Person"
                     }
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 25
+                },
+                "next": {
+                    "type": "string",
+                    "example": "/api/persons?limit=25\u0026page=3"
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "prev": {
+                    "type": "string",
+                    "example": "/api/persons?limit=25\u0026page=1"
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 120
+                }
+            }
+        },
+        "v1.idsResponse": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2
+                    ]
                 }
             }
         },
@@ -370,6 +1410,14 @@ const docTemplate = `{
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "AdminToken": {
+            "description": "Bearer followed by the ADMIN_TOKEN of the server",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
@@ -2,19 +2,25 @@ package app
 
 import (
 	"context"
+	"expvar"
 	"fio/internal/config"
 	delivery "fio/internal/delivery/http"
 	"fio/internal/delivery/kafka"
 	"fio/internal/repository"
 	"fio/internal/server"
 	"fio/internal/service"
+	"fio/internal/worker"
 	"fio/pkg/cache"
 	"fio/pkg/database/postgres"
 	"fio/pkg/database/redis"
 	"fio/pkg/profiler"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -22,6 +28,7 @@ import (
 
 	"github.com/IBM/sarama"
 	"github.com/go-playground/validator"
+	"github.com/jmoiron/sqlx"
 	"go.uber.org/zap"
 )
 
@@ -30,6 +37,10 @@ const (
 	genderizeResource   = "https://api.genderize.io"
 	nationalizeResource = "https://api.nationalize.io"
 	timeout             = 5 * time.Second
+	providerCache       = "cache"
+	providerRemote      = "remote"
+	providerDictionary  = "dictionary"
+	providerMorphology  = "morphology"
 )
 
 // @title Effective-Mobile Trainee Assignment
@@ -38,6 +49,11 @@ const (
 
 // @host localhost:8079
 // @BasePath /
+
+// @securityDefinitions.apikey AdminToken
+// @in header
+// @name Authorization
+// @description Bearer followed by the ADMIN_TOKEN of the server
 func Run(configDir string) {
 	zapLogger, err := zap.NewProduction()
 	if err != nil {
@@ -53,33 +69,23 @@ func Run(configDir string) {
 		return
 	}
 
-	db, err := postgres.NewPostgresqlDB(cfg.Postgres.Host, cfg.Postgres.Port, cfg.Postgres.Username,
-		cfg.Postgres.DBName, cfg.Postgres.Password, cfg.Postgres.SSLMode)
-	if err != nil {
-		logger.Errorf("Error occurred while loading Postgres DB: %s\n", err.Error())
-		return
-	}
-
-	rdb, err := redis.NewRedisClient(cfg.Redis.Address, cfg.Redis.Password, cfg.Redis.DB)
+	db, services, err := newServices(cfg, logger)
 	if err != nil {
-		logger.Errorf("Error occurred while loading Redis DB: %s\n", err.Error())
+		logger.Errorf("Error occurred while loading services: %s\n", err.Error())
 		return
 	}
-	cache := cache.NewRedisCache(rdb)
-	repos := repository.NewRepository(db)
-	prof := profiler.NewNameProfiler(agifyResource, genderizeResource, nationalizeResource, timeout)
-	services := service.NewService(service.Dependencies{
-		Repos:        repos,
-		Cache:        cache,
-		CacheTTL:     cfg.CacheTTL,
-		NameProfiler: prof,
-	})
 
 	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return strings.SplitN(field.Tag.Get("json"), ",", 2)[0] //nolint:gomnd
+	})
 
 	httpHandler := delivery.NewHandler(services, validate, logger)
 
-	mux := httpHandler.InitRoutes()
+	if cfg.HTTP.AdminToken == "" {
+		logger.Info("Admin API is off, set ADMIN_TOKEN to turn it on")
+	}
+	mux := httpHandler.InitRoutes(cfg.HTTP.AdminToken)
 
 	consumerGroup, err := queue.InitKafkaConsumerGroup(cfg.KafkaEndpoints, cfg.Kafka.GroupID, cfg.Kafka.ClientID,
 		cfg.Kafka.TLSEnable, cfg.Kafka.ReturnSucceses, sarama.RequiredAcks(cfg.Kafka.RequiredAcks))
@@ -97,11 +103,25 @@ func Run(configDir string) {
 
 	messageHandler := kafka.NewMessageHander(services, validate, logger, syncProducer)
 
-	consumeContext := context.Background()
-	go messageHandler.ConsumeLoop(cfg.KafkaTopics, consumeContext, consumerGroup)
-	defer consumeContext.Done()
+	// appContext is cancelled on shutdown, so in-flight enrichment and
+	// message handling stop waiting on the profiler.
+	appContext, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	consumeDone := make(chan struct{})
+	go func() {
+		defer close(consumeDone)
+		messageHandler.ConsumeLoop(cfg.KafkaTopics, appContext, consumerGroup)
+	}()
+
+	enrichmentPool := worker.NewEnrichmentPool(services.Enrichment, logger, cfg.Enrichment.Workers, cfg.Enrichment.Interval)
+	enrichmentDone := make(chan struct{})
+	go func() {
+		defer close(enrichmentDone)
+		enrichmentPool.Run(appContext)
+	}()
 
-	srv := server.NewServer(cfg, mux)
+	srv := server.NewServer(cfg, mux, appContext)
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
@@ -112,6 +132,16 @@ func Run(configDir string) {
 		}
 	}()
 
+	var debugSrv *server.Server
+	if cfg.HTTP.DebugAddress != "" {
+		debugSrv = server.NewDebugServer(cfg.HTTP.DebugAddress)
+		go func() {
+			if err := debugSrv.Run(); err != nil {
+				logger.Errorf("Failed to start debug server: %s\n", err.Error())
+			}
+		}()
+	}
+
 	logger.Info("Application is running")
 
 	<-quit
@@ -121,11 +151,140 @@ func Run(configDir string) {
 
 	logger.Info("Application is shutting down")
 
+	// in-flight requests are drained first, their contexts only end with
+	// appContext once they are done or the timeout is over
 	if err = srv.Shutdown(ctx); err != nil {
 		logger.Error(err.Error())
 	}
+	if debugSrv != nil {
+		if err = debugSrv.Shutdown(ctx); err != nil {
+			logger.Error(err.Error())
+		}
+	}
+	cancel()
+	// the workers and the consumer may be saving a batch, the database is
+	// closed after them
+	<-enrichmentDone
+	<-consumeDone
 
 	if err = db.Close(); err != nil {
 		logger.Error(err.Error())
 	}
 }
+
+// newServices connects to Postgres and Redis and builds the services on top.
+func newServices(cfg *config.Config, logger *zap.SugaredLogger) (*sqlx.DB, *service.Service, error) {
+	db, err := postgres.NewPostgresqlDB(cfg.Postgres.Host, cfg.Postgres.Port, cfg.Postgres.Username,
+		cfg.Postgres.DBName, cfg.Postgres.Password, cfg.Postgres.SSLMode)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load Postgres DB: %w", err)
+	}
+
+	rdb, err := redis.NewRedisClient(cfg.Redis.Address, cfg.Redis.Password, cfg.Redis.DB)
+	if err != nil {
+		db.Close() //nolint:errcheck
+		return nil, nil, fmt.Errorf("failed to load Redis DB: %w", err)
+	}
+	cache := cache.NewRedisCache(rdb)
+	repos := repository.NewRepository(db)
+	nameProfiler, err := newProfiler(cfg.Profiler, cache, logger)
+	if err != nil {
+		db.Close() //nolint:errcheck
+		return nil, nil, fmt.Errorf("failed to load profiler: %w", err)
+	}
+	services := service.NewService(service.Dependencies{
+		Repos:        repos,
+		Cache:        cache,
+		CacheTTL:     cfg.CacheTTL,
+		NameProfiler: nameProfiler,
+		Enrichment: service.EnrichmentSettings{
+			MinProbability: cfg.Profiler.MinProbability,
+			BatchSize:      cfg.Enrichment.BatchSize,
+			MaxAttempts:    cfg.Enrichment.MaxAttempts,
+			RetryDelay:     cfg.Enrichment.RetryDelay,
+			MaxRetryDelay:  cfg.Enrichment.MaxRetryDelay,
+			Lease:          cfg.Enrichment.Lease,
+			ReenrichRate:   cfg.Enrichment.ReenrichRate,
+		},
+	})
+	return db, services, nil
+}
+
+// newProfiler chains the providers configured for every attribute. The
+// public APIs are guarded by circuit breakers and cached, the local dataset
+// and the rules need neither. Providers are only built if they are used.
+func newProfiler(cfg config.ProfilerConfig, cache cache.Cache, logger *zap.SugaredLogger) (profiler.Profiler, error) {
+	built := make(map[string]profiler.Profiler)
+	provider := func(name string) (profiler.Provider, error) {
+		if prof, ok := built[name]; ok {
+			return profiler.Provider{Name: name, Profiler: prof}, nil
+		}
+
+		var prof profiler.Profiler
+		switch name {
+		case providerCache:
+			prof = profiler.NewCacheOnlyProfiler(cache)
+		case providerRemote:
+			prof = newRemoteProfiler(cfg, cache, logger)
+		case providerDictionary:
+			dict, err := profiler.NewDictionaryProfiler(cfg.Dictionary.Path)
+			if err != nil {
+				return profiler.Provider{}, err
+			}
+			prof = dict
+		case providerMorphology:
+			prof = profiler.NewMorphologyProfiler()
+		default:
+			return profiler.Provider{}, fmt.Errorf("unknown profiler provider %q", name)
+		}
+		built[name] = prof
+		return profiler.Provider{Name: name, Profiler: prof}, nil
+	}
+	providers := func(names []string) ([]profiler.Provider, error) {
+		list := make([]profiler.Provider, 0, len(names))
+		for _, name := range names {
+			p, err := provider(name)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, p)
+		}
+		return list, nil
+	}
+
+	settings := profiler.ChainSettings{MinProbability: cfg.MinProbability}
+	var err error
+	if settings.Age, err = providers(cfg.Chain.Age); err != nil {
+		return nil, err
+	}
+	if settings.Gender, err = providers(cfg.Chain.Gender); err != nil {
+		return nil, err
+	}
+	if settings.Nationality, err = providers(cfg.Chain.Nationality); err != nil {
+		return nil, err
+	}
+	return profiler.NewChainProfiler(settings), nil
+}
+
+// publishBreakers guards expvar.Publish, which panics on a second call with the same name.
+var publishBreakers sync.Once
+
+func newRemoteProfiler(cfg config.ProfilerConfig, cache cache.Cache, logger *zap.SugaredLogger) profiler.Profiler {
+	prof := profiler.NewNameProfiler(agifyResource, genderizeResource, nationalizeResource, timeout,
+		profiler.RetryPolicy{
+			MaxAttempts: cfg.Retry.MaxAttempts,
+			BaseDelay:   cfg.Retry.BaseDelay,
+			MaxDelay:    cfg.Retry.MaxDelay,
+		})
+	breakerProf := profiler.NewBreakerProfiler(prof, profiler.BreakerSettings{
+		FailureThreshold: cfg.Breaker.FailureThreshold,
+		OpenTimeout:      cfg.Breaker.OpenTimeout,
+		OnStateChange: func(upstream string, from, to profiler.BreakerState) {
+			logger.Warnf("Circuit breaker of %s changed state from %s to %s", upstream, from, to)
+		},
+	})
+	publishBreakers.Do(func() {
+		expvar.Publish("profiler_breakers", expvar.Func(func() interface{} { return breakerProf.States() }))
+	})
+	return profiler.NewCachingProfiler(breakerProf, cache, cfg.Cache.TTL, cfg.Cache.NegativeTTL)
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"context"
+	"fio/internal/config"
+	"fio/pkg/cache"
+	mock_cache "fio/pkg/cache/mocks"
+	"fio/pkg/profiler"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestNewProfiler(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	cch := mock_cache.NewMockCache(c)
+	cch.EXPECT().Get(gomock.Any(), "profiler:agify:dmitriy").Return(nil, cache.ErrItemNotFound)
+
+	prof, err := newProfiler(config.ProfilerConfig{
+		Chain: config.ChainConfig{
+			Age:         []string{providerCache, providerDictionary},
+			Gender:      []string{providerMorphology, providerDictionary},
+			Nationality: []string{providerDictionary, providerMorphology},
+		},
+		Dictionary: config.DictionaryConfig{Path: filepath.Join("..", "..", "configs", "names.csv")},
+	}, cch, zap.NewNop().Sugar())
+	assert.NoError(t, err)
+
+	got, err := prof.ProfileBatch(context.Background(), []profiler.Person{{Name: "Dmitriy", Surname: "Ivanova"}})
+	assert.NoError(t, err)
+	assert.Equal(t, profiler.AgifyResponse{Age: 45, Count: 16749, Source: providerDictionary}, got[0].Age)
+	assert.Equal(t, profiler.GenderizeResponse{Gender: "female", Probability: 0.9, Source: providerMorphology},
+		got[0].Gender)
+	assert.Equal(t, providerDictionary, got[0].Nationality.Source)
+	assert.Equal(t, "RU", got[0].Nationality.Country[0].CountryID)
+}
+
+func TestNewProfiler_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.ProfilerConfig
+		errorMsg string
+	}{
+		{
+			name:     "Unknown Provider",
+			cfg:      config.ProfilerConfig{Chain: config.ChainConfig{Gender: []string{providerMorphology, "oracle"}}},
+			errorMsg: `unknown profiler provider "oracle"`,
+		},
+		{
+			name: "Missing Dictionary",
+			cfg: config.ProfilerConfig{
+				Chain:      config.ChainConfig{Nationality: []string{providerDictionary}},
+				Dictionary: config.DictionaryConfig{Path: filepath.Join(t.TempDir(), "names.csv")},
+			},
+			errorMsg: "names.csv",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newProfiler(tt.cfg, nil, zap.NewNop().Sugar())
+			assert.ErrorContains(t, err, tt.errorMsg)
+		})
+	}
+}
+
+func TestNewProfiler_RemoteBuiltTwice(t *testing.T) {
+	cfg := config.ProfilerConfig{Chain: config.ChainConfig{
+		Age:         []string{providerRemote},
+		Gender:      []string{providerRemote},
+		Nationality: []string{providerRemote},
+	}}
+
+	for i := 0; i < 2; i++ {
+		_, err := newProfiler(cfg, nil, zap.NewNop().Sugar())
+		assert.NoError(t, err)
+	}
+}
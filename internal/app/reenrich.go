@@ -0,0 +1,124 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fio/internal/config"
+	"fio/internal/domain"
+	"fio/internal/worker"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const defaultProgressInterval = 5 * time.Second
+
+// Reenrich is the reenrich subcommand. It starts a run re-enriching the
+// persons matching the filters given as query params, or resumes the run given
+// by -run, and enriches the persons itself while reporting progress to out.
+// Workers of running servers share the work. An interrupted run is carried on
+// by the servers and can be watched again with -run.
+//
+//	fio reenrich [-rate 5] [-force] [-progress 5s] ["gender=male&nationality_in=RU"]
+//	fio reenrich -run 3
+func Reenrich(configDir string, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("reenrich", flag.ContinueOnError)
+	flags.SetOutput(out)
+	rate := flags.Float64("rate", 0, "persons enriched a second, the configured rate if omitted")
+	force := flags.Bool("force", false, "overwrite the fields an operator set")
+	runID := flags.Int("run", 0, "ID of a run to resume instead of starting a new one")
+	progress := flags.Duration("progress", defaultProgressInterval, "interval of progress reports")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	// zero stands for the configured rate, it can't be asked for
+	var rateSet bool
+	flags.Visit(func(f *flag.Flag) { rateSet = rateSet || f.Name == "rate" })
+	if rateSet && !(*rate > 0) {
+		return domain.ErrInvalidRate
+	}
+
+	query, err := url.ParseQuery(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("bad filters: %w", err)
+	}
+	filters, err := domain.ParsePersonFilters(query)
+	if err != nil {
+		return fmt.Errorf("bad filters: %w", err)
+	}
+
+	zapLogger, err := zap.NewProduction()
+	if err != nil {
+		return err
+	}
+	defer zapLogger.Sync() //nolint:errcheck
+	logger := zapLogger.Sugar()
+
+	cfg, err := config.InitConfig(configDir)
+	if err != nil {
+		return err
+	}
+
+	db, services, err := newServices(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer db.Close() //nolint:errcheck
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	var run domain.EnrichmentRun
+	if *runID != 0 {
+		run, err = services.Enrichment.ResumeEnrichmentRun(ctx, *runID)
+	} else {
+		run, err = services.Enrichment.Reenrich(ctx, filters, *rate, *force)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "run %d: %d persons at %g a second\n", run.ID, run.Total, run.Rate)
+
+	pool := worker.NewEnrichmentPool(services.Enrichment, logger, cfg.Enrichment.Workers, cfg.Enrichment.Interval)
+	poolDone := make(chan struct{})
+	go func() {
+		defer close(poolDone)
+		pool.Run(ctx)
+	}()
+	defer func() {
+		stop()
+		<-poolDone
+	}()
+
+	ticker := time.NewTicker(*progress)
+	defer ticker.Stop()
+	for !run.Finished() {
+		select {
+		case <-ctx.Done():
+			fmt.Fprintf(out, "run %d: interrupted, watch it again with -run %d\n", run.ID, run.ID)
+			return nil
+		case <-ticker.C:
+		}
+
+		if run, err = services.Enrichment.GetEnrichmentRun(ctx, run.ID); err != nil {
+			if ctx.Err() != nil {
+				continue
+			}
+			return err
+		}
+		fmt.Fprintf(out, "run %d: %d of %d done, %d pending, %d failed\n", run.ID, run.Done, run.Total, run.Pending, run.Failed)
+	}
+
+	fmt.Fprintf(out, "run %d: finished\n", run.ID)
+	return nil
+}
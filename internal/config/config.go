@@ -12,14 +12,33 @@ const (
 	defaultHTTPRWTimeout           = 10 * time.Second
 	defaultHTTPMaxHeaderMegabytes  = 1
 	defaultDatabaseRefreshInterval = 30 * time.Second
+	defaultRetryMaxAttempts        = 3
+	defaultRetryBaseDelay          = 200 * time.Millisecond
+	defaultRetryMaxDelay           = 2 * time.Second
+	defaultBreakerFailureThreshold = 5
+	defaultBreakerOpenTimeout      = 30 * time.Second
+	defaultProfilerCacheTTL        = 30 * 24 * time.Hour
+	defaultProfilerNegativeTTL     = 24 * time.Hour
+	defaultEnrichmentWorkers       = 4
+	defaultEnrichmentInterval      = time.Second
+	defaultEnrichmentBatchSize     = 10
+	defaultEnrichmentMaxAttempts   = 5
+	defaultEnrichmentRetryDelay    = 30 * time.Second
+	defaultEnrichmentMaxRetryDelay = time.Hour
+	defaultEnrichmentLease         = 5 * time.Minute
+	defaultEnrichmentReenrichRate  = 5
 )
 
+var defaultProfilerChain = []string{"remote"}
+
 type (
 	Config struct {
 		Postgres       PostgresConfig
 		HTTP           HTTPConfig
 		Kafka          KafkaConfig
 		Redis          RedisConfig
+		Profiler       ProfilerConfig
+		Enrichment     EnrichmentConfig
 		CacheTTL       time.Duration `mapstructure:"ttl"`
 		KafkaEndpoints []string      `mapstructure:"kafka-endpoints"`
 		KafkaTopics    []string      `mapstructure:"kafka-topics"`
@@ -40,6 +59,10 @@ type (
 		ReadTimeout        time.Duration `mapstructure:"readTimeout"`
 		WriteTimeout       time.Duration `mapstructure:"writeTimeout"`
 		MaxHeaderMegaBytes int           `mapstructure:"maxHeaderMegaBytes"`
+		// DebugAddress is where /debug/vars is served, empty turns it off.
+		DebugAddress string `mapstructure:"debugAddress"`
+		// AdminToken guards the admin API, empty turns the API off.
+		AdminToken string
 	}
 
 	RedisConfig struct {
@@ -48,6 +71,63 @@ type (
 		DB       int `mapstructure:"db"`
 	}
 
+	ProfilerConfig struct {
+		Chain      ChainConfig      `mapstructure:"chain"`
+		Dictionary DictionaryConfig `mapstructure:"dictionary"`
+		Retry      RetryConfig      `mapstructure:"retry"`
+		Breaker    BreakerConfig    `mapstructure:"breaker"`
+		Cache      CacheConfig      `mapstructure:"cache"`
+		// MinProbability leaves less likely guesses unknown, zero accepts any guess.
+		MinProbability float64 `mapstructure:"minProbability"`
+	}
+
+	// EnrichmentConfig tunes the workers enriching the persons saved as pending.
+	EnrichmentConfig struct {
+		Workers int `mapstructure:"workers"`
+		// Interval is the wait of an idle worker before it looks for due persons again.
+		Interval  time.Duration `mapstructure:"interval"`
+		BatchSize int           `mapstructure:"batchSize"`
+		// MaxAttempts is the number of tries before a person is marked as failed.
+		MaxAttempts int `mapstructure:"maxAttempts"`
+		// RetryDelay is doubled on every retry up to MaxRetryDelay.
+		RetryDelay    time.Duration `mapstructure:"retryDelay"`
+		MaxRetryDelay time.Duration `mapstructure:"maxRetryDelay"`
+		// Lease is how long a claimed batch is kept from other workers.
+		Lease time.Duration `mapstructure:"lease"`
+		// ReenrichRate is the number of persons re-enriched a second by runs
+		// started without a rate.
+		ReenrichRate float64 `mapstructure:"reenrichRate"`
+	}
+
+	// ChainConfig lists the providers asked for every attribute, in order:
+	// cache, remote, dictionary or morphology.
+	ChainConfig struct {
+		Age         []string `mapstructure:"age"`
+		Gender      []string `mapstructure:"gender"`
+		Nationality []string `mapstructure:"nationality"`
+	}
+
+	DictionaryConfig struct {
+		// Path of the .csv or .json name statistics dataset.
+		Path string `mapstructure:"path"`
+	}
+
+	CacheConfig struct {
+		TTL         time.Duration `mapstructure:"ttl"`
+		NegativeTTL time.Duration `mapstructure:"negativeTTL"`
+	}
+
+	BreakerConfig struct {
+		FailureThreshold int           `mapstructure:"failureThreshold"`
+		OpenTimeout      time.Duration `mapstructure:"openTimeout"`
+	}
+
+	RetryConfig struct {
+		MaxAttempts int           `mapstructure:"maxAttempts"`
+		BaseDelay   time.Duration `mapstructure:"baseDelay"`
+		MaxDelay    time.Duration `mapstructure:"maxDelay"`
+	}
+
 	KafkaConfig struct {
 		GroupID        string `mapstructure:"group-id"`
 		ClientID       string `mapstructure:"client-id"`
@@ -87,6 +167,14 @@ func unmarshal(cfg *Config) error {
 		return err
 	}
 
+	if err := viper.UnmarshalKey("profiler", &cfg.Profiler); err != nil {
+		return err
+	}
+
+	if err := viper.UnmarshalKey("enrichment", &cfg.Enrichment); err != nil {
+		return err
+	}
+
 	if err := viper.UnmarshalKey("kafka", &cfg.Kafka); err != nil {
 		return err
 	}
@@ -110,6 +198,7 @@ func setFromEnv(cfg *Config) {
 	cfg.Postgres.Port = os.Getenv("POSTGRES_PORT")
 	cfg.Postgres.Host = os.Getenv("POSTGRES_HOST")
 	cfg.HTTP.Host = os.Getenv("HTTP_HOST")
+	cfg.HTTP.AdminToken = os.Getenv("ADMIN_TOKEN")
 }
 
 func parseConfigFile(folder string) error {
@@ -129,4 +218,22 @@ func setDefaults() {
 	viper.SetDefault("http.readTimeout", defaultHTTPRWTimeout)
 	viper.SetDefault("http.writeTimeout", defaultHTTPRWTimeout)
 	viper.SetDefault("postgres.refreshInterval", defaultDatabaseRefreshInterval)
+	viper.SetDefault("profiler.chain.age", defaultProfilerChain)
+	viper.SetDefault("profiler.chain.gender", defaultProfilerChain)
+	viper.SetDefault("profiler.chain.nationality", defaultProfilerChain)
+	viper.SetDefault("profiler.retry.maxAttempts", defaultRetryMaxAttempts)
+	viper.SetDefault("profiler.retry.baseDelay", defaultRetryBaseDelay)
+	viper.SetDefault("profiler.retry.maxDelay", defaultRetryMaxDelay)
+	viper.SetDefault("profiler.breaker.failureThreshold", defaultBreakerFailureThreshold)
+	viper.SetDefault("profiler.breaker.openTimeout", defaultBreakerOpenTimeout)
+	viper.SetDefault("profiler.cache.ttl", defaultProfilerCacheTTL)
+	viper.SetDefault("profiler.cache.negativeTTL", defaultProfilerNegativeTTL)
+	viper.SetDefault("enrichment.workers", defaultEnrichmentWorkers)
+	viper.SetDefault("enrichment.interval", defaultEnrichmentInterval)
+	viper.SetDefault("enrichment.batchSize", defaultEnrichmentBatchSize)
+	viper.SetDefault("enrichment.maxAttempts", defaultEnrichmentMaxAttempts)
+	viper.SetDefault("enrichment.retryDelay", defaultEnrichmentRetryDelay)
+	viper.SetDefault("enrichment.maxRetryDelay", defaultEnrichmentMaxRetryDelay)
+	viper.SetDefault("enrichment.lease", defaultEnrichmentLease)
+	viper.SetDefault("enrichment.reenrichRate", defaultEnrichmentReenrichRate)
 }
@@ -0,0 +1,131 @@
+package v1
+
+import (
+	"fio/internal/domain"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// @Summary Start Enrichment Run
+// @Description Enriches the persons matching the filters again, all persons if there are none.
+// @Description The persons are enriched in the background at rate persons a second.
+// @Tags admin
+// @Security AdminToken
+// @ID	 start-enrichment-run
+// @Product json
+// @Param   filter  query domain.PersonFiltersQuery false "Query params"
+// @Param   rate    query number false "persons enriched a second, the configured rate if omitted" example(5)
+// @Param   force   query boolean false "overwrite the fields an operator set"
+// @Success	202		    {object}	domain.EnrichmentRun
+// @Failure	400			{object}	errorResponse
+// @Failure	401			{object}	errorResponse
+// @Failure	500			{object}	errorResponse
+// @Failure	default		{object}	errorResponse
+// @Router		/api/admin/enrichment-runs [post]
+func (h *Handler) startEnrichmentRun(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-type", appJSON)
+
+	filters, err := domain.ParsePersonFilters(r.URL.Query())
+	if err != nil {
+		h.newErrorResponse(w, err)
+		return
+	}
+
+	var rate float64
+	if value := r.URL.Query().Get("rate"); value != "" {
+		if rate, err = strconv.ParseFloat(value, 64); err != nil {
+			h.newErrorResponse(w, domain.ErrBadQuery)
+			return
+		}
+		// zero stands for the configured rate, it can't be asked for
+		if !(rate > 0) || math.IsInf(rate, 0) {
+			h.newErrorResponse(w, domain.ErrInvalidRate)
+			return
+		}
+	}
+
+	var force bool
+	if value := r.URL.Query().Get("force"); value != "" {
+		if force, err = strconv.ParseBool(value); err != nil {
+			h.newErrorResponse(w, domain.ErrBadQuery)
+			return
+		}
+	}
+
+	run, err := h.services.Enrichment.Reenrich(r.Context(), filters, rate, force)
+	if err != nil {
+		h.newErrorResponse(w, err)
+		return
+	}
+	h.logger.Infof("Enrichment run %d of %d persons was started", run.ID, run.Total)
+
+	w.Header().Set("Location", fmt.Sprintf("/api/admin/enrichment-runs/%d", run.ID))
+	newEnrichmentRunResponse(w, run, http.StatusAccepted)
+}
+
+// @Summary Get Enrichment Run
+// @Description Reports the progress of the run.
+// @Tags admin
+// @Security AdminToken
+// @ID	 get-enrichment-run
+// @Product json
+// @Param		runID	path		integer			true	"ID of the run"
+// @Success	200		    {object}	domain.EnrichmentRun
+// @Failure	400,404		{object}	errorResponse
+// @Failure	401			{object}	errorResponse
+// @Failure	500			{object}	errorResponse
+// @Failure	default		{object}	errorResponse
+// @Router		/api/admin/enrichment-runs/{runID} [get]
+func (h *Handler) getEnrichmentRun(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-type", appJSON)
+
+	runID, err := strconv.Atoi(mux.Vars(r)["runID"])
+	if err != nil {
+		h.newErrorResponse(w, errBadID)
+		return
+	}
+
+	run, err := h.services.Enrichment.GetEnrichmentRun(r.Context(), runID)
+	if err != nil {
+		h.newErrorResponse(w, err)
+		return
+	}
+
+	newEnrichmentRunResponse(w, run, http.StatusOK)
+}
+
+// @Summary Resume Enrichment Run
+// @Description Carries on with a stopped run, the persons still queued are spread out from now on at the rate of the run.
+// @Tags admin
+// @Security AdminToken
+// @ID	 resume-enrichment-run
+// @Product json
+// @Param		runID	path		integer			true	"ID of the run"
+// @Success	202		    {object}	domain.EnrichmentRun
+// @Failure	400,404		{object}	errorResponse
+// @Failure	401			{object}	errorResponse
+// @Failure	500			{object}	errorResponse
+// @Failure	default		{object}	errorResponse
+// @Router		/api/admin/enrichment-runs/{runID}/resume [post]
+func (h *Handler) resumeEnrichmentRun(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-type", appJSON)
+
+	runID, err := strconv.Atoi(mux.Vars(r)["runID"])
+	if err != nil {
+		h.newErrorResponse(w, errBadID)
+		return
+	}
+
+	run, err := h.services.Enrichment.ResumeEnrichmentRun(r.Context(), runID)
+	if err != nil {
+		h.newErrorResponse(w, err)
+		return
+	}
+	h.logger.Infof("Enrichment run %d was resumed", run.ID)
+
+	newEnrichmentRunResponse(w, run, http.StatusAccepted)
+}
@@ -0,0 +1,302 @@
+package v1
+
+import (
+	"errors"
+	"fio/internal/domain"
+	"fio/internal/service"
+	mock_service "fio/internal/service/mocks"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator"
+	"github.com/gorilla/mux"
+	"github.com/magiconair/properties/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestHandler_startEnrichmentRun(t *testing.T) {
+	type mockBehaviour func(se *mock_service.MockEnrichment)
+
+	createdAt := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	run := domain.EnrichmentRun{ID: 3, Filters: "gender=male", Rate: 5, Total: 10, Pending: 10, CreatedAt: createdAt}
+
+	tests := []struct {
+		name                 string
+		params               string
+		mockBehaviour        mockBehaviour
+		expectedStatusCode   int
+		expectedLocation     string
+		expectedResponseBody string
+	}{
+		{
+			name:   "OK",
+			params: "gender=male&rate=5",
+			mockBehaviour: func(se *mock_service.MockEnrichment) {
+				se.EXPECT().Reenrich(gomock.Any(), domain.PersonFiltersQuery{Gender: stringPointer("male")}, 5.0, false).Return(run, nil)
+			},
+			expectedStatusCode: 202,
+			expectedLocation:   "/api/admin/enrichment-runs/3",
+			expectedResponseBody: `{"id":3,"filters":"gender=male","rate":5,"force":false,"total":10,"done":0,"pending":10,"failed":0,` +
+				`"created_at":"2023-10-01T12:00:00Z"}`,
+		},
+		{
+			name:   "All Persons",
+			params: "",
+			mockBehaviour: func(se *mock_service.MockEnrichment) {
+				se.EXPECT().Reenrich(gomock.Any(), domain.PersonFiltersQuery{}, 0.0, false).Return(run, nil)
+			},
+			expectedStatusCode: 202,
+			expectedLocation:   "/api/admin/enrichment-runs/3",
+			expectedResponseBody: `{"id":3,"filters":"gender=male","rate":5,"force":false,"total":10,"done":0,"pending":10,"failed":0,` +
+				`"created_at":"2023-10-01T12:00:00Z"}`,
+		},
+		{
+			name:   "Forced",
+			params: "force=true",
+			mockBehaviour: func(se *mock_service.MockEnrichment) {
+				se.EXPECT().Reenrich(gomock.Any(), domain.PersonFiltersQuery{}, 0.0, true).Return(run, nil)
+			},
+			expectedStatusCode: 202,
+			expectedLocation:   "/api/admin/enrichment-runs/3",
+			expectedResponseBody: `{"id":3,"filters":"gender=male","rate":5,"force":false,"total":10,"done":0,"pending":10,"failed":0,` +
+				`"created_at":"2023-10-01T12:00:00Z"}`,
+		},
+		{
+			name:                 "Bad Force",
+			params:               "force=maybe",
+			mockBehaviour:        func(se *mock_service.MockEnrichment) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"bad query","code":"validation"}`,
+		},
+		{
+			name:                 "Bad Rate",
+			params:               "rate=fast",
+			mockBehaviour:        func(se *mock_service.MockEnrichment) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"bad query","code":"validation"}`,
+		},
+		{
+			name:                 "Zero Rate",
+			params:               "rate=0",
+			mockBehaviour:        func(se *mock_service.MockEnrichment) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"rate must be a positive number","code":"validation"}`,
+		},
+		{
+			name:                 "Negative Rate",
+			params:               "rate=-1",
+			mockBehaviour:        func(se *mock_service.MockEnrichment) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"rate must be a positive number","code":"validation"}`,
+		},
+		{
+			name:                 "NaN Rate",
+			params:               "rate=NaN",
+			mockBehaviour:        func(se *mock_service.MockEnrichment) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"rate must be a positive number","code":"validation"}`,
+		},
+		{
+			name:                 "Infinite Rate",
+			params:               "rate=Inf",
+			mockBehaviour:        func(se *mock_service.MockEnrichment) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"rate must be a positive number","code":"validation"}`,
+		},
+		{
+			name:                 "Negative Infinite Rate",
+			params:               "rate=-Inf",
+			mockBehaviour:        func(se *mock_service.MockEnrichment) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"rate must be a positive number","code":"validation"}`,
+		},
+		{
+			name:                 "Bad Filters",
+			params:               "age_min=65&age_max=18",
+			mockBehaviour:        func(se *mock_service.MockEnrichment) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"age_min is greater than age_max","code":"validation"}`,
+		},
+		{
+			name:   "Service Error",
+			params: "",
+			mockBehaviour: func(se *mock_service.MockEnrichment) {
+				se.EXPECT().Reenrich(gomock.Any(), domain.PersonFiltersQuery{}, 0.0, false).Return(domain.EnrichmentRun{}, errors.New("something went wrong"))
+			},
+			expectedStatusCode:   500,
+			expectedResponseBody: `{"title":"Internal Server Error","status":500,"detail":"internal server error","code":"internal"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			serviceEnrichment := mock_service.NewMockEnrichment(c)
+			test.mockBehaviour(serviceEnrichment)
+
+			services := &service.Service{Enrichment: serviceEnrichment}
+
+			validate := validator.New()
+			logger := zap.NewNop().Sugar()
+			h := NewHandler(services, validate, logger)
+
+			r := mux.NewRouter()
+			r.HandleFunc("/api/admin/enrichment-runs", h.startEnrichmentRun).Methods("POST")
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/admin/enrichment-runs?"+test.params, nil)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, test.expectedStatusCode)
+			assert.Equal(t, w.Header().Get("Location"), test.expectedLocation)
+			assert.Equal(t, w.Body.String(), test.expectedResponseBody)
+		})
+	}
+}
+
+func TestHandler_getEnrichmentRun(t *testing.T) {
+	type mockBehaviour func(se *mock_service.MockEnrichment, runID int)
+
+	createdAt := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                 string
+		paramID              string
+		inputID              int
+		mockBehaviour        mockBehaviour
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:    "OK",
+			paramID: "3",
+			inputID: 3,
+			mockBehaviour: func(se *mock_service.MockEnrichment, runID int) {
+				se.EXPECT().GetEnrichmentRun(gomock.Any(), runID).Return(domain.EnrichmentRun{
+					ID: 3, Rate: 5, Total: 10, Done: 7, Pending: 2, Failed: 1, CreatedAt: createdAt}, nil)
+			},
+			expectedStatusCode: 200,
+			expectedResponseBody: `{"id":3,"filters":"","rate":5,"force":false,"total":10,"done":7,"pending":2,"failed":1,` +
+				`"created_at":"2023-10-01T12:00:00Z"}`,
+		},
+		{
+			name:                 "Bad ID",
+			paramID:              "three",
+			mockBehaviour:        func(se *mock_service.MockEnrichment, runID int) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"bad id","code":"validation"}`,
+		},
+		{
+			name:    "Not Found",
+			paramID: "3",
+			inputID: 3,
+			mockBehaviour: func(se *mock_service.MockEnrichment, runID int) {
+				se.EXPECT().GetEnrichmentRun(gomock.Any(), runID).Return(domain.EnrichmentRun{}, domain.ErrEnrichmentRunNotFound)
+			},
+			expectedStatusCode:   404,
+			expectedResponseBody: `{"title":"Not Found","status":404,"detail":"enrichment run not found","code":"not_found"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			serviceEnrichment := mock_service.NewMockEnrichment(c)
+			test.mockBehaviour(serviceEnrichment, test.inputID)
+
+			services := &service.Service{Enrichment: serviceEnrichment}
+
+			validate := validator.New()
+			logger := zap.NewNop().Sugar()
+			h := NewHandler(services, validate, logger)
+
+			r := mux.NewRouter()
+			r.HandleFunc("/api/admin/enrichment-runs/{runID}", h.getEnrichmentRun).Methods("GET")
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", fmt.Sprintf("/api/admin/enrichment-runs/%s", test.paramID), nil)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, test.expectedStatusCode)
+			assert.Equal(t, w.Body.String(), test.expectedResponseBody)
+		})
+	}
+}
+
+func TestHandler_adminAuthorization(t *testing.T) {
+	tests := []struct {
+		name                 string
+		adminToken           string
+		authorization        string
+		called               bool
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:                 "OK",
+			adminToken:           "secret",
+			authorization:        "Bearer secret",
+			called:               true,
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"id":3,"filters":"","rate":5,"force":false,"total":0,"done":0,"pending":0,"failed":0,"created_at":"0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name:                 "No Token",
+			adminToken:           "secret",
+			expectedStatusCode:   401,
+			expectedResponseBody: `{"title":"Unauthorized","status":401,"detail":"admin token required","code":"unauthorized"}`,
+		},
+		{
+			name:                 "Wrong Token",
+			adminToken:           "secret",
+			authorization:        "Bearer guess",
+			expectedStatusCode:   401,
+			expectedResponseBody: `{"title":"Unauthorized","status":401,"detail":"admin token required","code":"unauthorized"}`,
+		},
+		{
+			name:                 "Not A Bearer Token",
+			adminToken:           "secret",
+			authorization:        "secret",
+			expectedStatusCode:   401,
+			expectedResponseBody: `{"title":"Unauthorized","status":401,"detail":"admin token required","code":"unauthorized"}`,
+		},
+		{
+			name:                 "Admin API Off",
+			authorization:        "Bearer ",
+			expectedStatusCode:   404,
+			expectedResponseBody: "404 page not found\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			serviceEnrichment := mock_service.NewMockEnrichment(c)
+			if test.called {
+				serviceEnrichment.EXPECT().GetEnrichmentRun(gomock.Any(), 3).Return(domain.EnrichmentRun{ID: 3, Rate: 5}, nil)
+			}
+
+			services := &service.Service{Enrichment: serviceEnrichment}
+			h := NewHandler(services, validator.New(), zap.NewNop().Sugar())
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/admin/enrichment-runs/3", nil)
+			if test.authorization != "" {
+				req.Header.Set("Authorization", test.authorization)
+			}
+			h.InitRoutes(test.adminToken).ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, test.expectedStatusCode)
+			assert.Equal(t, w.Body.String(), test.expectedResponseBody)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fio/internal/domain"
+	"fmt"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/go-playground/validator"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+var errBadInput = domain.NewValidationError("bad input")
+
+// ErrorPresenter adds the domain error code, e.g. NOT_FOUND, and the field
+// details to the error extensions.
+func ErrorPresenter(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+
+	var domainErr *domain.Error
+	if !errors.As(err, &domainErr) {
+		return gqlErr
+	}
+
+	if gqlErr.Extensions == nil {
+		gqlErr.Extensions = map[string]interface{}{}
+	}
+	gqlErr.Extensions["code"] = strings.ToUpper(string(domainErr.Code))
+	if len(domainErr.Fields) > 0 {
+		gqlErr.Extensions["fields"] = domainErr.Fields
+	}
+	return gqlErr
+}
+
+// validationError lists the fields the validator rejected, the same way the
+// REST API does.
+func validationError(err error) error {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return errBadInput
+	}
+
+	fields := make([]domain.FieldError, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		fields = append(fields, domain.FieldError{
+			Field:   fieldErr.Field(),
+			Message: fmt.Sprintf("failed on the '%s' rule", fieldErr.Tag()),
+		})
+	}
+
+	return &domain.Error{Code: domain.CodeValidation, Err: errBadInput, Fields: fields}
+}
@@ -2,8 +2,43 @@
 
 package model
 
+import (
+	"fio/internal/domain"
+)
+
+type CursorPageInfo struct {
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	StartCursor     *string `json:"startCursor,omitempty"`
+	EndCursor       *string `json:"endCursor,omitempty"`
+}
+
 type NewPerson struct {
 	Name       string  `json:"name"`
 	Surname    string  `json:"surname"`
 	Patronymic *string `json:"patronymic,omitempty"`
 }
+
+type PageInfo struct {
+	Page            int  `json:"page"`
+	Limit           int  `json:"limit"`
+	HasNextPage     bool `json:"hasNextPage"`
+	HasPreviousPage bool `json:"hasPreviousPage"`
+}
+
+type PersonConnection struct {
+	Edges      []PersonEdge    `json:"edges"`
+	PageInfo   *CursorPageInfo `json:"pageInfo"`
+	TotalCount int             `json:"totalCount"`
+}
+
+type PersonEdge struct {
+	Cursor string         `json:"cursor"`
+	Node   *domain.Person `json:"node"`
+}
+
+type PersonPage struct {
+	Data       []domain.Person `json:"data"`
+	TotalCount int             `json:"totalCount"`
+	PageInfo   *PageInfo       `json:"pageInfo"`
+}
@@ -17,13 +17,15 @@ func (r *mutationResolver) AddPerson(ctx context.Context, input model.NewPerson)
 		Surname:    input.Surname,
 		Patronymic: input.Patronymic,
 	}
-	r.validator.Struct(person)
-	return r.services.Person.Add(person)
+	if err := r.validator.Struct(person); err != nil {
+		return 0, validationError(err)
+	}
+	return r.services.Person.Add(ctx, person)
 }
 
 // DeletePerson is the resolver for the deletePerson field.
 func (r *mutationResolver) DeletePerson(ctx context.Context, id int) (bool, error) {
-	return r.services.Person.Delete(id)
+	return r.services.Person.Delete(ctx, id)
 }
 
 // UpdatePerson is the resolver for the updatePerson field.
@@ -32,25 +34,118 @@ func (r *mutationResolver) UpdatePerson(ctx context.Context, id int, input domai
 	if err != nil {
 		return false, err
 	}
-	return r.services.Person.Update(id, input)
+	err = input.ValidateAttributes()
+	if err != nil {
+		return false, err
+	}
+	return r.services.Person.Update(ctx, id, input)
 }
 
 // GetPersons is the resolver for the getPersons field.
-func (r *queryResolver) GetPersons(ctx context.Context, filter *domain.PersonFiltersQuery, pagination *domain.PaginationQuery) ([]domain.Person, error) {
-	if filter == nil {
-		filter = &domain.PersonFiltersQuery{}
+func (r *queryResolver) GetPersons(ctx context.Context, filter *domain.PersonFiltersQuery, pagination *domain.PaginationQuery, sort *string) ([]domain.Person, error) {
+	opts, err := personsQuery(filter, pagination, sort)
+	if err != nil {
+		return nil, err
+	}
+	return r.services.Person.GetAll(ctx, opts)
+}
+
+// GetPersonsPage is the resolver for the getPersonsPage field.
+func (r *queryResolver) GetPersonsPage(ctx context.Context, filter *domain.PersonFiltersQuery, pagination *domain.PaginationQuery, sort *string) (*model.PersonPage, error) {
+	opts, err := personsQuery(filter, pagination, sort)
+	if err != nil {
+		return nil, err
+	}
+
+	persons, err := r.services.Person.GetAll(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := r.services.Person.Count(ctx, opts.PersonFiltersQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PersonPage{
+		Data:       persons,
+		TotalCount: total,
+		PageInfo: &model.PageInfo{
+			Page:            opts.Page(),
+			Limit:           opts.Limit,
+			HasNextPage:     opts.Offset+len(persons) < total,
+			HasPreviousPage: opts.Offset > 0,
+		},
+	}, nil
+}
+
+// GetPersonsConnection is the resolver for the getPersonsConnection field.
+func (r *queryResolver) GetPersonsConnection(ctx context.Context, filter *domain.PersonFiltersQuery, sort *string, first *int, after *string, last *int, before *string) (*model.PersonConnection, error) {
+	opts, err := personsCursorQuery(filter, sort, first, after, last, before)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := r.services.Person.GetAllByCursor(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := r.services.Person.Count(ctx, opts.PersonFiltersQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	edges := make([]model.PersonEdge, 0, len(conn.Persons))
+	for i := range conn.Persons {
+		edges = append(edges, model.PersonEdge{
+			Cursor: domain.NewCursor(conn.Persons[i], opts.Sort).Encode(),
+			Node:   &conn.Persons[i],
+		})
 	}
-	if pagination == nil {
-		pagination = &domain.PaginationQuery{
-			Limit:  defaultLimit,
-			Offset: (defaultPage - 1) * defaultLimit,
-		}
+
+	pageInfo := &model.CursorPageInfo{
+		HasNextPage:     conn.HasNextPage,
+		HasPreviousPage: conn.HasPreviousPage,
+	}
+	if len(edges) > 0 {
+		pageInfo.StartCursor = &conn.StartCursor
+		pageInfo.EndCursor = &conn.EndCursor
+	}
+
+	return &model.PersonConnection{
+		Edges:      edges,
+		PageInfo:   pageInfo,
+		TotalCount: total,
+	}, nil
+}
+
+// Person is the resolver for the person field.
+func (r *queryResolver) Person(ctx context.Context, id int) (*domain.Person, error) {
+	person, err := r.services.Person.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
 	}
-	opts := domain.PersonsQuery{
-		PaginationQuery:    *pagination,
-		PersonFiltersQuery: *filter,
+	return &person, nil
+}
+
+// EnrichmentStatus is the resolver for the enrichmentStatus field.
+func (r *personResolver) EnrichmentStatus(ctx context.Context, obj *domain.Person) (string, error) {
+	return string(obj.EnrichmentStatus), nil
+}
+
+// Match is the resolver for the match field.
+func (r *personFilterResolver) Match(ctx context.Context, obj *domain.PersonFiltersQuery, data *string) error {
+	if data != nil {
+		obj.Match = domain.MatchMode(*data)
 	}
-	return r.services.Person.GetAll(opts)
+	return nil
+}
+
+// Patronymic is the resolver for the patronymic field.
+func (r *updatePersonResolver) Patronymic(ctx context.Context, obj *domain.UpdatePersonInput, data *string) error {
+	obj.Patronymic = domain.NewNullString(data)
+	return nil
 }
 
 // Mutation returns MutationResolver implementation.
@@ -59,5 +154,17 @@ func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
 // Query returns QueryResolver implementation.
 func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
+// Person returns PersonResolver implementation.
+func (r *Resolver) Person() PersonResolver { return &personResolver{r} }
+
+// PersonFilter returns PersonFilterResolver implementation.
+func (r *Resolver) PersonFilter() PersonFilterResolver { return &personFilterResolver{r} }
+
+// UpdatePerson returns UpdatePersonResolver implementation.
+func (r *Resolver) UpdatePerson() UpdatePersonResolver { return &updatePersonResolver{r} }
+
 type mutationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type personResolver struct{ *Resolver }
+type personFilterResolver struct{ *Resolver }
+type updatePersonResolver struct{ *Resolver }
@@ -1,6 +1,7 @@
 package graph
 
 import (
+	"fio/internal/domain"
 	"fio/internal/service"
 
 	"github.com/go-playground/validator"
@@ -17,6 +18,8 @@ const (
 	defaultPage  = 1
 )
 
+var errFirstAndLast = domain.NewValidationError("first and last can't be used together")
+
 type Resolver struct {
 	services  *service.Service
 	validator *validator.Validate
@@ -30,3 +33,83 @@ func NewResolver(services *service.Service, validator *validator.Validate, logge
 		logger:    logger,
 	}
 }
+
+// personsQuery fills in defaults for optional listing arguments.
+func personsQuery(filter *domain.PersonFiltersQuery, pagination *domain.PaginationQuery, sort *string) (domain.PersonsQuery, error) {
+	var sortFields []domain.SortField
+	if sort != nil {
+		var err error
+		sortFields, err = domain.ParseSort(*sort)
+		if err != nil {
+			return domain.PersonsQuery{}, err
+		}
+	}
+	if filter == nil {
+		filter = &domain.PersonFiltersQuery{}
+	}
+	if err := filter.Validate(); err != nil {
+		return domain.PersonsQuery{}, err
+	}
+	if pagination == nil {
+		pagination = &domain.PaginationQuery{
+			Limit:  defaultLimit,
+			Offset: (defaultPage - 1) * defaultLimit,
+		}
+	}
+	if pagination.Limit < 1 {
+		pagination.Limit = defaultLimit
+	}
+	if pagination.Limit > maxLimit {
+		pagination.Limit = maxLimit
+	}
+
+	return domain.PersonsQuery{
+		PaginationQuery:    *pagination,
+		PersonFiltersQuery: *filter,
+		Sort:               sortFields,
+	}, nil
+}
+
+// personsCursorQuery maps Relay style connection arguments to a keyset query.
+func personsCursorQuery(filter *domain.PersonFiltersQuery, sort *string,
+	first *int, after *string, last *int, before *string) (domain.PersonsCursorQuery, error) {
+	if first != nil && last != nil {
+		return domain.PersonsCursorQuery{}, errFirstAndLast
+	}
+
+	opts, err := personsQuery(filter, nil, sort)
+	if err != nil {
+		return domain.PersonsCursorQuery{}, err
+	}
+
+	cursorQuery := domain.CursorQuery{Limit: defaultLimit, Backward: last != nil}
+	switch {
+	case first != nil:
+		cursorQuery.Limit = *first
+	case last != nil:
+		cursorQuery.Limit = *last
+	}
+	if cursorQuery.Limit < 1 {
+		cursorQuery.Limit = defaultLimit
+	}
+	if cursorQuery.Limit > maxLimit {
+		cursorQuery.Limit = maxLimit
+	}
+
+	if after != nil {
+		if cursorQuery.After, err = domain.DecodeCursor(*after, opts.Sort); err != nil {
+			return domain.PersonsCursorQuery{}, err
+		}
+	}
+	if before != nil {
+		if cursorQuery.Before, err = domain.DecodeCursor(*before, opts.Sort); err != nil {
+			return domain.PersonsCursorQuery{}, err
+		}
+	}
+
+	return domain.PersonsCursorQuery{
+		CursorQuery:        cursorQuery,
+		PersonFiltersQuery: opts.PersonFiltersQuery,
+		Sort:               opts.Sort,
+	}, nil
+}
@@ -1,7 +1,9 @@
 package v1
 
 import (
+	"errors"
 	"fio/internal/delivery/http/graph"
+	"fio/internal/domain"
 	"net/http"
 
 	"fio/internal/service"
@@ -18,7 +20,20 @@ import (
 )
 
 const (
-	appJSON = "application/json"
+	appJSON        = "application/json"
+	appMergePatch  = "application/merge-patch+json"
+	appProblemJSON = "application/problem+json"
+)
+
+var (
+	errBadID          = domain.NewValidationError("bad id")
+	errBadInput       = domain.NewValidationError("bad input")
+	errBadPayload     = domain.NewValidationError("can't unpack payload")
+	errUnknownPayload = domain.NewValidationError("unknown payload")
+	errUnauthorized   = domain.NewError(domain.CodeUnauthorized, errors.New("admin token required"))
+	errCreatePayload  = errors.New("can't create payload")
+	errWriteResponse  = errors.New("can't write resp")
+	errPanic          = errors.New("panic")
 )
 
 type Handler struct {
@@ -35,18 +50,31 @@ func NewHandler(services *service.Service, validator *validator.Validate, logger
 	}
 }
 
-func (h *Handler) InitRoutes() http.Handler {
+// InitRoutes mounts the admin API only if there is an adminToken, its
+// requests must carry it as a bearer token.
+func (h *Handler) InitRoutes(adminToken string) http.Handler {
 	r := mux.NewRouter()
 
 	r.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
 
 	r.HandleFunc("/api/persons", h.paginationMiddleware(h.getPersons)).Methods("GET")
+	r.HandleFunc("/api/persons", h.addPersons).Methods("POST")
+	r.HandleFunc("/api/persons/cursor", h.paginationMiddleware(h.getPersonsByCursor)).Methods("GET")
 	r.HandleFunc("/api/person", h.addPerson).Methods("POST")
+	r.HandleFunc("/api/person/{personID}", h.getPerson).Methods("GET")
 	r.HandleFunc("/api/person/{personID}", h.deletePerson).Methods("DELETE")
-	r.HandleFunc("/api/person/{personID}", h.updatePerson).Methods("PUT")
+	r.HandleFunc("/api/person/{personID}", h.replacePerson).Methods("PUT")
+	r.HandleFunc("/api/person/{personID}", h.updatePerson).Methods("PATCH")
+	if adminToken != "" {
+		r.HandleFunc("/api/admin/enrichment-runs", h.adminMiddleware(adminToken, h.startEnrichmentRun)).Methods("POST")
+		r.HandleFunc("/api/admin/enrichment-runs/{runID}", h.adminMiddleware(adminToken, h.getEnrichmentRun)).Methods("GET")
+		r.HandleFunc("/api/admin/enrichment-runs/{runID}/resume",
+			h.adminMiddleware(adminToken, h.resumeEnrichmentRun)).Methods("POST")
+	}
 
 	srv := handler.NewDefaultServer(graph.NewExecutableSchema(graph.Config{
 		Resolvers: graph.NewResolver(h.services, h.validator, h.logger)}))
+	srv.SetErrorPresenter(graph.ErrorPresenter)
 
 	r.Handle("/", playground.Handler("Test Effective-Mobile", "/query"))
 	r.Handle("/query", srv)
@@ -1,9 +1,12 @@
 package v1
 
 import (
+	"crypto/subtle"
 	"fio/internal/domain"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -19,7 +22,7 @@ func (h *Handler) panicMiddleware(next http.Handler) http.Handler {
 		defer func() {
 			if err := recover(); err != nil {
 				h.logger.Infow("recovered", err)
-				newErrorResponse(w, `Internal server error`, http.StatusInternalServerError)
+				h.newErrorResponse(w, errPanic)
 			}
 		}()
 		next.ServeHTTP(w, r)
@@ -40,6 +43,21 @@ func (h *Handler) accessLogMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// adminMiddleware lets through only the requests with the admin token in the
+// Authorization header.
+func (h *Handler) adminMiddleware(token string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			h.logger.Warnw("admin request without a valid token", "url", r.URL.Path, "remote_addr", r.RemoteAddr)
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			h.newErrorResponse(w, errUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
 func (h *Handler) paginationMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		h.logger.Infow("query middleware", r.URL.Path)
@@ -76,3 +94,21 @@ func parseInt(value string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// pageLink points at the same listing with another page, keeping all other params.
+func pageLink(u *url.URL, page, limit int) string {
+	query := u.Query()
+	query.Set("page", strconv.Itoa(page))
+	query.Set("limit", strconv.Itoa(limit))
+	return u.Path + "?" + query.Encode()
+}
+
+// cursorLink points at the same listing read from the cursor in the given direction.
+func cursorLink(u *url.URL, direction, cursor string, limit int) string {
+	query := u.Query()
+	query.Del("after")
+	query.Del("before")
+	query.Set(direction, cursor)
+	query.Set("limit", strconv.Itoa(limit))
+	return u.Path + "?" + query.Encode()
+}
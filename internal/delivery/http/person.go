@@ -2,13 +2,14 @@ package v1
 
 import (
 	"encoding/json"
+	"errors"
 	"fio/internal/domain"
+	"fmt"
 	"io"
 	"net/http"
 	"strconv"
 
 	"github.com/gorilla/mux"
-	"github.com/gorilla/schema"
 )
 
 // @Summary Get Persons
@@ -18,6 +19,7 @@ import (
 // @Param   filter  query domain.PersonFiltersQuery false "Query params"
 // @Param   limit   query int false "limit" Enums(10, 25, 50)
 // @Param   page  query int false "page"
+// @Param   sort  query string false "comma separated sort fields, prefix with '-' for descending" example(-age,surname)
 // @Success	200		    {object}	getPersonsResponse
 // @Failure	400,404		{object}	errorResponse
 // @Failure	500			{object}	errorResponse
@@ -26,32 +28,225 @@ import (
 func (h *Handler) getPersons(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-type", appJSON)
 
-	var filter domain.PersonFiltersQuery
-	decoder := schema.NewDecoder()
-	decoder.IgnoreUnknownKeys(true)
-	err := decoder.Decode(&filter, r.URL.Query())
+	filter, sort, err := parseFilterAndSort(r)
 	if err != nil {
-		newErrorResponse(w, "bad query", http.StatusBadRequest)
+		h.newErrorResponse(w, err)
 		return
 	}
 
 	pagination, err := domain.PaginationFromContext(r.Context())
 	if err != nil {
-		newErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		h.newErrorResponse(w, err)
 		return
 	}
 
-	opts := domain.PersonsQuery{PaginationQuery: *pagination, PersonFiltersQuery: filter}
-	persons, err := h.services.Person.GetAll(opts)
+	opts := domain.PersonsQuery{PaginationQuery: *pagination, PersonFiltersQuery: filter, Sort: sort}
+	persons, err := h.services.Person.GetAll(r.Context(), opts)
 	if err != nil {
-		newErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		h.newErrorResponse(w, err)
 		return
 	}
 
-	newGetPersonsResponse(w, persons, http.StatusOK)
+	total, err := h.services.Person.Count(r.Context(), filter)
+	if err != nil {
+		h.newErrorResponse(w, err)
+		return
+	}
+
+	page := pagination.Page()
+	resp := getPersonsResponse{
+		Data:  persons,
+		Total: total,
+		Page:  page,
+		Limit: pagination.Limit,
+	}
+	if pagination.Offset+len(persons) < total {
+		resp.Next = pageLink(r.URL, page+1, pagination.Limit)
+	}
+	if page > 1 {
+		resp.Prev = pageLink(r.URL, page-1, pagination.Limit)
+	}
+
+	newGetPersonsResponse(w, resp, http.StatusOK)
+}
+
+// @Summary Get Persons By Cursor
+// @Tags person
+// @ID	 get-persons-by-cursor
+// @Product json
+// @Param   filter  query domain.PersonFiltersQuery false "Query params"
+// @Param   limit   query int false "limit" Enums(10, 25, 50)
+// @Param   after   query string false "cursor to read forward from"
+// @Param   before  query string false "cursor to read backward from"
+// @Param   sort  query string false "comma separated sort fields, prefix with '-' for descending" example(-age,surname)
+// @Success	200		    {object}	getPersonsCursorResponse
+// @Failure	400,404		{object}	errorResponse
+// @Failure	500			{object}	errorResponse
+// @Failure	default		{object}	errorResponse
+// @Router		/api/persons/cursor [get]
+func (h *Handler) getPersonsByCursor(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-type", appJSON)
+
+	filter, sort, err := parseFilterAndSort(r)
+	if err != nil {
+		h.newErrorResponse(w, err)
+		return
+	}
+
+	pagination, err := domain.PaginationFromContext(r.Context())
+	if err != nil {
+		h.newErrorResponse(w, err)
+		return
+	}
+
+	cursorQuery := domain.CursorQuery{Limit: pagination.Limit}
+	if after := r.URL.Query().Get("after"); after != "" {
+		if cursorQuery.After, err = domain.DecodeCursor(after, sort); err != nil {
+			h.newErrorResponse(w, err)
+			return
+		}
+	}
+	if before := r.URL.Query().Get("before"); before != "" {
+		if cursorQuery.Before, err = domain.DecodeCursor(before, sort); err != nil {
+			h.newErrorResponse(w, err)
+			return
+		}
+		cursorQuery.Backward = cursorQuery.After == nil
+	}
+
+	opts := domain.PersonsCursorQuery{CursorQuery: cursorQuery, PersonFiltersQuery: filter, Sort: sort}
+	conn, err := h.services.Person.GetAllByCursor(r.Context(), opts)
+	if err != nil {
+		h.newErrorResponse(w, err)
+		return
+	}
+
+	resp := getPersonsCursorResponse{
+		Data:        conn.Persons,
+		StartCursor: conn.StartCursor,
+		EndCursor:   conn.EndCursor,
+		HasNext:     conn.HasNextPage,
+		HasPrev:     conn.HasPreviousPage,
+	}
+	if conn.HasNextPage && conn.EndCursor != "" {
+		resp.Next = cursorLink(r.URL, "after", conn.EndCursor, pagination.Limit)
+	}
+	if conn.HasPreviousPage && conn.StartCursor != "" {
+		resp.Prev = cursorLink(r.URL, "before", conn.StartCursor, pagination.Limit)
+	}
+
+	newGetPersonsCursorResponse(w, resp, http.StatusOK)
+}
+
+// @Summary Get Person
+// @Tags person
+// @ID	 get-person
+// @Product json
+// @Param		personID	path		integer			true	"ID of person to get"
+// @Success	200		    {object}	domain.Person
+// @Failure	400,404		{object}	errorResponse
+// @Failure	500			{object}	errorResponse
+// @Failure	default		{object}	errorResponse
+// @Router		/api/person/{personID} [get]
+func (h *Handler) getPerson(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-type", appJSON)
+
+	vars := mux.Vars(r)
+	personID, err := strconv.Atoi(vars["personID"])
+	if err != nil {
+		h.newErrorResponse(w, errBadID)
+		return
+	}
+
+	person, err := h.services.Person.GetByID(r.Context(), personID)
+	if err != nil {
+		h.newErrorResponse(w, err)
+		return
+	}
+
+	newPersonResponse(w, person, http.StatusOK)
+}
+
+// @Summary Add Persons
+// @Description Adds up to 100 persons at once, either all of them or none.
+// @Description The persons are saved as pending, age, gender and nationality are filled in later.
+// @Description A person with keep_attributes keeps the ones it was given, only the missing ones are filled in.
+// @Tags person
+// @ID	 add-persons
+// @Accept json
+// @Product json
+// @Param   input body []domain.Person true "Persons content"
+// @Success	200		    {object}	idsResponse
+// @Failure	400,404		{object}	errorResponse
+// @Failure	409			{object}	errorResponse
+// @Failure	500			{object}	errorResponse
+// @Failure	default		{object}	errorResponse
+// @Router		/api/persons [post]
+func (h *Handler) addPersons(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-type", appJSON)
+	if r.Header.Get("Content-Type") != appJSON {
+		h.newErrorResponse(w, errUnknownPayload)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.newErrorResponse(w, errBadInput)
+		return
+	}
+	r.Body.Close()
+
+	var persons []domain.Person
+	err = json.Unmarshal(body, &persons)
+	if err != nil {
+		h.newErrorResponse(w, errBadPayload)
+		return
+	}
+
+	var fields []domain.FieldError
+	for i, person := range persons {
+		if err = h.validator.Struct(person); err != nil {
+			for _, field := range domain.FieldsOf(validationError(err)) {
+				field.Field = fmt.Sprintf("[%d].%s", i, field.Field)
+				fields = append(fields, field)
+			}
+		}
+		if !person.KeepAttributes {
+			continue
+		}
+		for _, field := range domain.FieldsOf(person.ValidateAttributes()) {
+			field.Field = fmt.Sprintf("[%d].%s", i, field.Field)
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) > 0 {
+		h.newErrorResponse(w, &domain.Error{Code: domain.CodeValidation, Err: errBadInput, Fields: fields})
+		return
+	}
+
+	ids, err := h.services.Person.AddBatch(r.Context(), persons)
+	if err != nil {
+		h.newErrorResponse(w, err)
+		return
+	}
+	h.logger.Infof("%d persons were added", len(ids))
+
+	resp, err := json.Marshal(idsResponse{ids})
+	if err != nil {
+		h.newErrorResponse(w, errCreatePayload)
+		return
+	}
+
+	_, err = w.Write(resp)
+	if err != nil {
+		h.newErrorResponse(w, errWriteResponse)
+		return
+	}
 }
 
 // @Summary Add Person
+// @Description The person is saved as pending, age, gender and nationality are filled in later.
+// @Description With keep_attributes the given ones are kept, only the missing ones are filled in.
 // @Tags person
 // @ID	 add-person
 // @Accept json
@@ -59,19 +254,20 @@ func (h *Handler) getPersons(w http.ResponseWriter, r *http.Request) {
 // @Param   input body domain.Person true "Person content"
 // @Success	200		    {integer}	integer     "id"
 // @Failure	400,404		{object}	errorResponse
+// @Failure	409			{object}	errorResponse
 // @Failure	500			{object}	errorResponse
 // @Failure	default		{object}	errorResponse
 // @Router		/api/person [post]
 func (h *Handler) addPerson(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-type", appJSON)
 	if r.Header.Get("Content-Type") != appJSON {
-		newErrorResponse(w, "unknown payload", http.StatusBadRequest)
+		h.newErrorResponse(w, errUnknownPayload)
 		return
 	}
 
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		newErrorResponse(w, "bad input", http.StatusBadRequest)
+		h.newErrorResponse(w, errBadInput)
 		return
 	}
 	r.Body.Close()
@@ -79,32 +275,40 @@ func (h *Handler) addPerson(w http.ResponseWriter, r *http.Request) {
 	var person domain.Person
 	err = json.Unmarshal(body, &person)
 	if err != nil {
-		newErrorResponse(w, "can't unpack payload", http.StatusBadRequest)
+		h.newErrorResponse(w, errBadPayload)
 		return
 	}
 
 	err = h.validator.Struct(person)
 	if err != nil {
-		newErrorResponse(w, "bad input", http.StatusBadRequest)
+		h.newErrorResponse(w, validationError(err))
 		return
 	}
 
-	id, err := h.services.Person.Add(person)
+	if person.KeepAttributes {
+		err = person.ValidateAttributes()
+		if err != nil {
+			h.newErrorResponse(w, err)
+			return
+		}
+	}
+
+	id, err := h.services.Person.Add(r.Context(), person)
 	if err != nil {
-		newErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		h.newErrorResponse(w, err)
 		return
 	}
 	h.logger.Infof("Person with ID %d was added", id)
 
 	resp, err := json.Marshal(idResponse{id})
 	if err != nil {
-		newErrorResponse(w, `can't create payload`, http.StatusInternalServerError)
+		h.newErrorResponse(w, errCreatePayload)
 		return
 	}
 
 	_, err = w.Write(resp)
 	if err != nil {
-		newErrorResponse(w, `can't write resp`, http.StatusInternalServerError)
+		h.newErrorResponse(w, errWriteResponse)
 		return
 	}
 }
@@ -125,26 +329,25 @@ func (h *Handler) deletePerson(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	personID, err := strconv.Atoi(vars["personID"])
 	if err != nil {
-		newErrorResponse(w, "bad id", http.StatusBadRequest)
+		h.newErrorResponse(w, errBadID)
 		return
 	}
 
-	deleted, err := h.services.Person.Delete(personID)
+	deleted, err := h.services.Person.Delete(r.Context(), personID)
 	if err != nil {
-		newErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		h.newErrorResponse(w, err)
 		return
 	}
-	if deleted {
-		h.logger.Infof("Person with ID %d was deleted: %v", personID, deleted)
-	}
+	h.logger.Infof("Person with ID %d was deleted: %v", personID, deleted)
 
 	newStatusReponse(w, "done", http.StatusOK)
 }
 
 // @Summary Update Person
+// @Description Applies a JSON Merge Patch: omitted fields are left as is, null clears the patronymic
 // @Tags person
 // @ID	 update-person
-// @Accept json
+// @Accept json,application/merge-patch+json
 // @Product json
 // @Param		personID	path		integer			true	"ID of person to update"
 // @Param		input		body		domain.UpdatePersonInput	false	"Update Person content"
@@ -152,17 +355,17 @@ func (h *Handler) deletePerson(w http.ResponseWriter, r *http.Request) {
 // @Failure	400,404		{object}	errorResponse
 // @Failure	500			{object}	errorResponse
 // @Failure	default		{object}	errorResponse
-// @Router		/api/person/{personID} [put]
+// @Router		/api/person/{personID} [patch]
 func (h *Handler) updatePerson(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-type", appJSON)
-	if r.Header.Get("Content-Type") != appJSON {
-		newErrorResponse(w, "unknown payload", http.StatusBadRequest)
+	if contentType := r.Header.Get("Content-Type"); contentType != appJSON && contentType != appMergePatch {
+		h.newErrorResponse(w, errUnknownPayload)
 		return
 	}
 
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		newErrorResponse(w, "bad input", http.StatusBadRequest)
+		h.newErrorResponse(w, errBadInput)
 		return
 	}
 	r.Body.Close()
@@ -170,31 +373,121 @@ func (h *Handler) updatePerson(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	personID, err := strconv.Atoi(vars["personID"])
 	if err != nil {
-		newErrorResponse(w, "bad id", http.StatusBadRequest)
+		h.newErrorResponse(w, errBadID)
 		return
 	}
 
 	var inp domain.UpdatePersonInput
 	err = json.Unmarshal(body, &inp)
+	if errors.Is(err, domain.ErrNullField) {
+		h.newErrorResponse(w, err)
+		return
+	}
 	if err != nil {
-		newErrorResponse(w, "can't unpack payload", http.StatusBadRequest)
+		h.newErrorResponse(w, errBadPayload)
 		return
 	}
 
 	err = inp.Validate()
 	if err != nil {
-		newErrorResponse(w, err.Error(), http.StatusBadRequest)
+		h.newErrorResponse(w, err)
 		return
 	}
 
-	updated, err := h.services.Person.Update(personID, inp)
+	err = inp.ValidateAttributes()
 	if err != nil {
-		newErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		h.newErrorResponse(w, err)
 		return
 	}
-	if updated {
-		h.logger.Infof("Person with ID %d was updated: %v", personID, updated)
+
+	updated, err := h.services.Person.Update(r.Context(), personID, inp)
+	if err != nil {
+		h.newErrorResponse(w, err)
+		return
 	}
+	h.logger.Infof("Person with ID %d was updated: %v", personID, updated)
 
 	newStatusReponse(w, "done", http.StatusOK)
 }
+
+// @Summary Replace Person
+// @Tags person
+// @ID	 replace-person
+// @Accept json
+// @Product json
+// @Param		personID	path		integer			true	"ID of person to replace"
+// @Param		input		body		domain.Person	true	"Person content"
+// @Success	200		    {object}	statusResponse
+// @Failure	400,404		{object}	errorResponse
+// @Failure	500			{object}	errorResponse
+// @Failure	default		{object}	errorResponse
+// @Router		/api/person/{personID} [put]
+func (h *Handler) replacePerson(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-type", appJSON)
+	if r.Header.Get("Content-Type") != appJSON {
+		h.newErrorResponse(w, errUnknownPayload)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.newErrorResponse(w, errBadInput)
+		return
+	}
+	r.Body.Close()
+
+	vars := mux.Vars(r)
+	personID, err := strconv.Atoi(vars["personID"])
+	if err != nil {
+		h.newErrorResponse(w, errBadID)
+		return
+	}
+
+	var person domain.Person
+	err = json.Unmarshal(body, &person)
+	if err != nil {
+		h.newErrorResponse(w, errBadPayload)
+		return
+	}
+
+	err = h.validator.Struct(person)
+	if err != nil {
+		h.newErrorResponse(w, validationError(err))
+		return
+	}
+
+	err = person.Validate()
+	if err != nil {
+		h.newErrorResponse(w, err)
+		return
+	}
+
+	err = person.ValidateAttributes()
+	if err != nil {
+		h.newErrorResponse(w, err)
+		return
+	}
+
+	replaced, err := h.services.Person.Replace(r.Context(), personID, person)
+	if err != nil {
+		h.newErrorResponse(w, err)
+		return
+	}
+	h.logger.Infof("Person with ID %d was replaced: %v", personID, replaced)
+
+	newStatusReponse(w, "done", http.StatusOK)
+}
+
+func parseFilterAndSort(r *http.Request) (domain.PersonFiltersQuery, []domain.SortField, error) {
+	filter, err := domain.ParsePersonFilters(r.URL.Query())
+	if err != nil {
+		return domain.PersonFiltersQuery{}, nil, err
+	}
+
+	sort, err := domain.ParseSort(r.URL.Query().Get("sort"))
+	if err != nil {
+		return domain.PersonFiltersQuery{}, nil, err
+	}
+
+	return filter, sort, nil
+}
@@ -3,6 +3,7 @@ package v1
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fio/internal/domain"
 	"fio/internal/service"
@@ -33,19 +34,115 @@ func TestHandler_getPersons(t *testing.T) {
 			name:              "OK",
 			inputPersonsQuery: domain.PersonsQuery{},
 			mockBehaviour: func(su *mock_service.MockPerson, opts domain.PersonsQuery) {
-				su.EXPECT().GetAll(opts).Return([]domain.Person{{ID: 1, Name: "Test", Surname: "Test", Age: 5, Gender: "male", Nationality: "RU"}}, nil)
+				su.EXPECT().GetAll(gomock.Any(), opts).Return([]domain.Person{{ID: 1, Name: "Test", Surname: "Test", Age: 5, Gender: "male", Nationality: "RU"}}, nil)
+				su.EXPECT().Count(gomock.Any(), opts.PersonFiltersQuery).Return(1, nil)
 			},
 			expectedStatusCode:   200,
-			expectedResponseBody: `{"data":[{"ID":1,"name":"Test","surname":"Test","age":5,"gender":"male","nationality":"RU"}]}`,
+			expectedResponseBody: `{"data":[{"ID":1,"name":"Test","surname":"Test","age":5,"gender":"male","nationality":"RU"}],"total":1,"page":1,"limit":0}`,
+		},
+		{
+			name: "Page Links",
+			inputPersonsQuery: domain.PersonsQuery{
+				PaginationQuery:    domain.PaginationQuery{Limit: 1, Offset: 1},
+				PersonFiltersQuery: domain.PersonFiltersQuery{Gender: stringPointer("male")},
+			},
+			params: map[string]string{"gender": "male"},
+			mockBehaviour: func(su *mock_service.MockPerson, opts domain.PersonsQuery) {
+				su.EXPECT().GetAll(gomock.Any(), opts).Return([]domain.Person{{ID: 2, Name: "Test", Surname: "Test", Age: 5, Gender: "male", Nationality: "RU"}}, nil)
+				su.EXPECT().Count(gomock.Any(), opts.PersonFiltersQuery).Return(3, nil)
+			},
+			expectedStatusCode: 200,
+			expectedResponseBody: `{"data":[{"ID":2,"name":"Test","surname":"Test","age":5,"gender":"male","nationality":"RU"}],"total":3,"page":2,"limit":1,` +
+				`"next":"/api/persons?gender=male\u0026limit=1\u0026page=3","prev":"/api/persons?gender=male\u0026limit=1\u0026page=1"}`,
+		},
+		{
+			name:              "Count Error",
+			inputPersonsQuery: domain.PersonsQuery{},
+			mockBehaviour: func(su *mock_service.MockPerson, opts domain.PersonsQuery) {
+				su.EXPECT().GetAll(gomock.Any(), opts).Return([]domain.Person{}, nil)
+				su.EXPECT().Count(gomock.Any(), opts.PersonFiltersQuery).Return(0, errors.New("something went wrong"))
+			},
+			expectedStatusCode:   500,
+			expectedResponseBody: `{"title":"Internal Server Error","status":500,"detail":"internal server error","code":"internal"}`,
 		},
 		{
 			name:              "Service Error",
 			inputPersonsQuery: domain.PersonsQuery{},
 			mockBehaviour: func(su *mock_service.MockPerson, opts domain.PersonsQuery) {
-				su.EXPECT().GetAll(opts).Return(nil, errors.New("something went wrong"))
+				su.EXPECT().GetAll(gomock.Any(), opts).Return(nil, errors.New("something went wrong"))
 			},
 			expectedStatusCode:   500,
-			expectedResponseBody: `{"message":"something went wrong"}`,
+			expectedResponseBody: `{"title":"Internal Server Error","status":500,"detail":"internal server error","code":"internal"}`,
+		},
+		{
+			name: "Sorted",
+			inputPersonsQuery: domain.PersonsQuery{
+				Sort: []domain.SortField{{Field: "age", Desc: true}, {Field: "surname"}},
+			},
+			params: map[string]string{"sort": "-age,surname"},
+			mockBehaviour: func(su *mock_service.MockPerson, opts domain.PersonsQuery) {
+				su.EXPECT().GetAll(gomock.Any(), opts).Return([]domain.Person{}, nil)
+				su.EXPECT().Count(gomock.Any(), opts.PersonFiltersQuery).Return(0, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"data":[],"total":0,"page":1,"limit":0}`,
+		},
+		{
+			name: "Range And Set Filters",
+			inputPersonsQuery: domain.PersonsQuery{
+				PersonFiltersQuery: domain.PersonFiltersQuery{
+					AgeMin:        intPointer(18),
+					AgeMax:        intPointer(65),
+					NationalityIn: []string{"RU", "UA"},
+				},
+			},
+			params: map[string]string{"age_min": "18", "age_max": "65", "nationality_in": "RU,UA"},
+			mockBehaviour: func(su *mock_service.MockPerson, opts domain.PersonsQuery) {
+				su.EXPECT().GetAll(gomock.Any(), opts).Return([]domain.Person{}, nil)
+				su.EXPECT().Count(gomock.Any(), opts.PersonFiltersQuery).Return(0, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"data":[],"total":0,"page":1,"limit":0}`,
+		},
+		{
+			name:                 "Bad Age Range",
+			inputPersonsQuery:    domain.PersonsQuery{},
+			params:               map[string]string{"age_min": "65", "age_max": "18"},
+			mockBehaviour:        func(su *mock_service.MockPerson, opts domain.PersonsQuery) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"age_min is greater than age_max","code":"validation"}`,
+		},
+		{
+			name: "Case Insensitive Match",
+			inputPersonsQuery: domain.PersonsQuery{
+				PersonFiltersQuery: domain.PersonFiltersQuery{
+					Name:  stringPointer("dmitriy"),
+					Match: domain.MatchIExact,
+				},
+			},
+			params: map[string]string{"name": "dmitriy", "match": "iexact"},
+			mockBehaviour: func(su *mock_service.MockPerson, opts domain.PersonsQuery) {
+				su.EXPECT().GetAll(gomock.Any(), opts).Return([]domain.Person{}, nil)
+				su.EXPECT().Count(gomock.Any(), opts.PersonFiltersQuery).Return(0, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"data":[],"total":0,"page":1,"limit":0}`,
+		},
+		{
+			name:                 "Bad Match",
+			inputPersonsQuery:    domain.PersonsQuery{},
+			params:               map[string]string{"name": "dmitriy", "match": "regex"},
+			mockBehaviour:        func(su *mock_service.MockPerson, opts domain.PersonsQuery) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"invalid match mode","code":"validation"}`,
+		},
+		{
+			name:                 "Bad Sort",
+			inputPersonsQuery:    domain.PersonsQuery{},
+			params:               map[string]string{"sort": "-patronymic"},
+			mockBehaviour:        func(su *mock_service.MockPerson, opts domain.PersonsQuery) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"invalid sort field","code":"validation"}`,
 		},
 	}
 
@@ -73,6 +170,221 @@ func TestHandler_getPersons(t *testing.T) {
 			for k, v := range test.params {
 				q.Add(k, v)
 			}
+			req.URL.RawQuery = q.Encode()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, test.expectedStatusCode)
+			assert.Equal(t, w.Body.String(), test.expectedResponseBody)
+		})
+	}
+}
+
+func TestHandler_getPersonsByCursor(t *testing.T) {
+	type mockBehaviour func(su *mock_service.MockPerson, opts domain.PersonsCursorQuery)
+
+	person := domain.Person{ID: 7, Name: "Test", Surname: "Test", Age: 5, Gender: "male", Nationality: "RU"}
+	sort := []domain.SortField{{Field: "age", Desc: true}}
+	cursor := domain.NewCursor(person, sort)
+	cursor.Values = []interface{}{json.Number("5")}
+	mistyped := domain.NewCursor(person, sort)
+	mistyped.Values = []interface{}{"5; DROP TABLE persons"}
+	fractional := domain.NewCursor(person, sort)
+	fractional.Values = []interface{}{json.Number("5.5")}
+
+	tests := []struct {
+		name                 string
+		inputQuery           domain.PersonsCursorQuery
+		mockBehaviour        mockBehaviour
+		params               map[string]string
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			inputQuery: domain.PersonsCursorQuery{
+				CursorQuery: domain.CursorQuery{Limit: 1, After: &cursor},
+				Sort:        sort,
+			},
+			params: map[string]string{"sort": "-age", "after": cursor.Encode()},
+			mockBehaviour: func(su *mock_service.MockPerson, opts domain.PersonsCursorQuery) {
+				su.EXPECT().GetAllByCursor(gomock.Any(), opts).Return(domain.PersonsConnection{
+					Persons:         []domain.Person{person},
+					StartCursor:     "start",
+					EndCursor:       "end",
+					HasNextPage:     true,
+					HasPreviousPage: true,
+				}, nil)
+			},
+			expectedStatusCode: 200,
+			expectedResponseBody: `{"data":[{"ID":7,"name":"Test","surname":"Test","age":5,"gender":"male","nationality":"RU"}],` +
+				`"start_cursor":"start","end_cursor":"end","has_next":true,"has_prev":true,` +
+				`"next":"/api/persons/cursor?after=end\u0026limit=1\u0026sort=-age","prev":"/api/persons/cursor?before=start\u0026limit=1\u0026sort=-age"}`,
+		},
+		{
+			name:                 "Cursor For Another Sort",
+			inputQuery:           domain.PersonsCursorQuery{CursorQuery: domain.CursorQuery{Limit: 1}},
+			params:               map[string]string{"after": cursor.Encode()},
+			mockBehaviour:        func(su *mock_service.MockPerson, opts domain.PersonsCursorQuery) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"invalid cursor","code":"validation"}`,
+		},
+		{
+			name:                 "Mistyped Cursor Value",
+			inputQuery:           domain.PersonsCursorQuery{CursorQuery: domain.CursorQuery{Limit: 1}},
+			params:               map[string]string{"sort": "-age", "after": mistyped.Encode()},
+			mockBehaviour:        func(su *mock_service.MockPerson, opts domain.PersonsCursorQuery) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"invalid cursor","code":"validation"}`,
+		},
+		{
+			name:                 "Fractional Cursor Value",
+			inputQuery:           domain.PersonsCursorQuery{CursorQuery: domain.CursorQuery{Limit: 1}},
+			params:               map[string]string{"sort": "-age", "after": fractional.Encode()},
+			mockBehaviour:        func(su *mock_service.MockPerson, opts domain.PersonsCursorQuery) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"invalid cursor","code":"validation"}`,
+		},
+		{
+			name:                 "Malformed Cursor",
+			inputQuery:           domain.PersonsCursorQuery{CursorQuery: domain.CursorQuery{Limit: 1}},
+			params:               map[string]string{"before": "!!!"},
+			mockBehaviour:        func(su *mock_service.MockPerson, opts domain.PersonsCursorQuery) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"invalid cursor","code":"validation"}`,
+		},
+		{
+			name:       "Service Error",
+			inputQuery: domain.PersonsCursorQuery{CursorQuery: domain.CursorQuery{Limit: 1}},
+			mockBehaviour: func(su *mock_service.MockPerson, opts domain.PersonsCursorQuery) {
+				su.EXPECT().GetAllByCursor(gomock.Any(), opts).Return(domain.PersonsConnection{}, errors.New("something went wrong"))
+			},
+			expectedStatusCode:   500,
+			expectedResponseBody: `{"title":"Internal Server Error","status":500,"detail":"internal server error","code":"internal"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			servicePerson := mock_service.NewMockPerson(c)
+			test.mockBehaviour(servicePerson, test.inputQuery)
+
+			services := &service.Service{Person: servicePerson}
+
+			validate := validator.New()
+			logger := zap.NewNop().Sugar()
+			h := NewHandler(services, validate, logger)
+
+			r := mux.NewRouter()
+			r.HandleFunc("/api/persons/cursor", h.getPersonsByCursor).Methods("GET")
+
+			w := httptest.NewRecorder()
+			pagination := &domain.PaginationQuery{Limit: test.inputQuery.Limit}
+			ctx := context.WithValue(context.TODO(), domain.PaginationContextKey, pagination)
+			req := httptest.NewRequest("GET", "/api/persons/cursor", nil).WithContext(ctx)
+			q := req.URL.Query()
+			for k, v := range test.params {
+				q.Add(k, v)
+			}
+			req.URL.RawQuery = q.Encode()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, test.expectedStatusCode)
+			assert.Equal(t, w.Body.String(), test.expectedResponseBody)
+		})
+	}
+}
+
+func TestHandler_getPerson(t *testing.T) {
+	type mockBehaviour func(su *mock_service.MockPerson, personID int)
+
+	tests := []struct {
+		name                 string
+		paramID              string
+		inputID              int
+		mockBehaviour        mockBehaviour
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:    "OK",
+			paramID: "1",
+			inputID: 1,
+			mockBehaviour: func(su *mock_service.MockPerson, personID int) {
+				su.EXPECT().GetByID(gomock.Any(), personID).Return(domain.Person{ID: 1, Name: "Test", Surname: "Test", Age: 5, Gender: "male", Nationality: "RU"}, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"ID":1,"name":"Test","surname":"Test","age":5,"gender":"male","nationality":"RU"}`,
+		},
+		{
+			name:    "With Enrichment",
+			paramID: "1",
+			inputID: 1,
+			mockBehaviour: func(su *mock_service.MockPerson, personID int) {
+				probability := 0.9
+				su.EXPECT().GetByID(gomock.Any(), personID).Return(domain.Person{ID: 1, Name: "Test", Surname: "Test", Age: 5, Gender: "male",
+					Enrichment: &domain.Enrichment{
+						Age:         domain.Confidence{Count: 10},
+						Gender:      domain.Confidence{Probability: &probability, Count: 20},
+						Nationality: domain.Confidence{Count: 30, Alternatives: []domain.Alternative{{Value: "RU", Probability: 0.4}}},
+					}}, nil)
+			},
+			expectedStatusCode: 200,
+			expectedResponseBody: `{"ID":1,"name":"Test","surname":"Test","age":5,"gender":"male","nationality":"",` +
+				`"enrichment":{"age":{"count":10},"gender":{"probability":0.9,"count":20},` +
+				`"nationality":{"count":30,"alternatives":[{"value":"RU","probability":0.4}]}}}`,
+		},
+		{
+			name:                 "Bad ID",
+			paramID:              "1d",
+			inputID:              1,
+			mockBehaviour:        func(su *mock_service.MockPerson, personID int) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"bad id","code":"validation"}`,
+		},
+		{
+			name:    "Not Found",
+			paramID: "1",
+			inputID: 1,
+			mockBehaviour: func(su *mock_service.MockPerson, personID int) {
+				su.EXPECT().GetByID(gomock.Any(), personID).Return(domain.Person{}, domain.ErrPersonNotFound)
+			},
+			expectedStatusCode:   404,
+			expectedResponseBody: `{"title":"Not Found","status":404,"detail":"person not found","code":"not_found"}`,
+		},
+		{
+			name:    "Service Error",
+			paramID: "1",
+			inputID: 1,
+			mockBehaviour: func(su *mock_service.MockPerson, personID int) {
+				su.EXPECT().GetByID(gomock.Any(), personID).Return(domain.Person{}, errors.New("something went wrong"))
+			},
+			expectedStatusCode:   500,
+			expectedResponseBody: `{"title":"Internal Server Error","status":500,"detail":"internal server error","code":"internal"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			servicePerson := mock_service.NewMockPerson(c)
+			test.mockBehaviour(servicePerson, test.inputID)
+
+			services := &service.Service{Person: servicePerson}
+
+			validate := validator.New()
+			logger := zap.NewNop().Sugar()
+			h := NewHandler(services, validate, logger)
+
+			r := mux.NewRouter()
+			r.HandleFunc("/api/person/{personID}", h.getPerson).Methods("GET")
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", fmt.Sprintf("/api/person/%s", test.paramID), nil)
 			r.ServeHTTP(w, req)
 
 			assert.Equal(t, w.Code, test.expectedStatusCode)
@@ -100,7 +412,7 @@ func TestHandler_addPerson(t *testing.T) {
 				Surname: "test",
 			},
 			mockBehaviour: func(su *mock_service.MockPerson, person domain.Person) {
-				su.EXPECT().Add(person).Return(1, nil)
+				su.EXPECT().Add(gomock.Any(), person).Return(1, nil)
 			},
 			expectedStatusCode:   200,
 			expectedResponseBody: `{"id":1}`,
@@ -111,7 +423,70 @@ func TestHandler_addPerson(t *testing.T) {
 			inputPerson:          domain.Person{},
 			mockBehaviour:        func(su *mock_service.MockPerson, person domain.Person) {},
 			expectedStatusCode:   400,
-			expectedResponseBody: `{"message":"bad input"}`,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"bad input","code":"validation","errors":[{"field":"Name","message":"failed on the 'required' rule"},{"field":"Surname","message":"failed on the 'required' rule"}]}`,
+		},
+		{
+			name:      "Attributes Kept",
+			inputBody: `{"name":"alex", "surname":"test", "gender":"male", "nationality":"RU", "keep_attributes":true}`,
+			inputPerson: domain.Person{
+				Name:           "alex",
+				Surname:        "test",
+				Gender:         "male",
+				Nationality:    "RU",
+				KeepAttributes: true,
+			},
+			mockBehaviour: func(su *mock_service.MockPerson, person domain.Person) {
+				su.EXPECT().Add(gomock.Any(), person).Return(1, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"id":1}`,
+		},
+		{
+			name:                 "Bad Attributes",
+			inputBody:            `{"name":"alex", "surname":"test", "age":-1, "gender":"other", "nationality":"XX", "keep_attributes":true}`,
+			mockBehaviour:        func(su *mock_service.MockPerson, person domain.Person) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"bad attributes","code":"validation","errors":[{"field":"Age","message":"must not be negative"},{"field":"Gender","message":"must be male or female"},{"field":"Nationality","message":"must be an ISO 3166-1 alpha-2 country code"}]}`,
+		},
+		{
+			name:      "Attributes Not Checked Unless Kept",
+			inputBody: `{"name":"alex", "surname":"test", "gender":"other"}`,
+			inputPerson: domain.Person{
+				Name:    "alex",
+				Surname: "test",
+				Gender:  "other",
+			},
+			mockBehaviour: func(su *mock_service.MockPerson, person domain.Person) {
+				su.EXPECT().Add(gomock.Any(), person).Return(1, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"id":1}`,
+		},
+		{
+			name:      "Profiler Unavailable",
+			inputBody: `{"name":"alex", "surname":"test"}`,
+			inputPerson: domain.Person{
+				Name:    "alex",
+				Surname: "test",
+			},
+			mockBehaviour: func(su *mock_service.MockPerson, person domain.Person) {
+				su.EXPECT().Add(gomock.Any(), person).Return(0, domain.NewError(domain.CodeUpstreamUnavailable, errors.New("HTTP request failed with status: 502")))
+			},
+			expectedStatusCode:   503,
+			expectedResponseBody: `{"title":"Service Unavailable","status":503,"detail":"HTTP request failed with status: 502","code":"upstream_unavailable"}`,
+		},
+		{
+			name:      "Already Exists",
+			inputBody: `{"name":"alex", "surname":"test"}`,
+			inputPerson: domain.Person{
+				Name:    "alex",
+				Surname: "test",
+			},
+			mockBehaviour: func(su *mock_service.MockPerson, person domain.Person) {
+				su.EXPECT().Add(gomock.Any(), person).Return(0, domain.NewError(domain.CodeConflict, errors.New("already exists")))
+			},
+			expectedStatusCode:   409,
+			expectedResponseBody: `{"title":"Conflict","status":409,"detail":"already exists","code":"conflict"}`,
 		},
 		{
 			name:      "Service Error",
@@ -121,10 +496,10 @@ func TestHandler_addPerson(t *testing.T) {
 				Surname: "test",
 			},
 			mockBehaviour: func(su *mock_service.MockPerson, person domain.Person) {
-				su.EXPECT().Add(person).Return(0, errors.New("something went wrong"))
+				su.EXPECT().Add(gomock.Any(), person).Return(0, errors.New("something went wrong"))
 			},
 			expectedStatusCode:   500,
-			expectedResponseBody: `{"message":"something went wrong"}`,
+			expectedResponseBody: `{"title":"Internal Server Error","status":500,"detail":"internal server error","code":"internal"}`,
 		},
 	}
 
@@ -151,6 +526,107 @@ func TestHandler_addPerson(t *testing.T) {
 			req.Header.Set("Content-Type", appJSON)
 			r.ServeHTTP(w, req)
 
+			assert.Equal(t, w.Code, test.expectedStatusCode)
+			if test.expectedStatusCode != 200 {
+				assert.Equal(t, appProblemJSON, w.Header().Get("Content-type"))
+			}
+			assert.Equal(t, w.Body.String(), test.expectedResponseBody)
+		})
+	}
+}
+
+func TestHandler_addPersons(t *testing.T) {
+	type mockBehaviour func(su *mock_service.MockPerson, persons []domain.Person)
+
+	tests := []struct {
+		name                 string
+		inputBody            string
+		inputPersons         []domain.Person
+		mockBehaviour        mockBehaviour
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `[{"name":"alex", "surname":"test"},{"name":"anna", "surname":"test"}]`,
+			inputPersons: []domain.Person{
+				{Name: "alex", Surname: "test"},
+				{Name: "anna", Surname: "test"},
+			},
+			mockBehaviour: func(su *mock_service.MockPerson, persons []domain.Person) {
+				su.EXPECT().AddBatch(gomock.Any(), persons).Return([]int{1, 2}, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"ids":[1,2]}`,
+		},
+		{
+			name:                 "Wrong Input",
+			inputBody:            `[{"name":"alex", "surname":"test"},{"name":"anna"}]`,
+			mockBehaviour:        func(su *mock_service.MockPerson, persons []domain.Person) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"bad input","code":"validation","errors":[{"field":"[1].Surname","message":"failed on the 'required' rule"}]}`,
+		},
+		{
+			name:                 "Bad Attributes",
+			inputBody:            `[{"name":"alex", "surname":"test"},{"name":"anna", "surname":"test", "nationality":"Russia", "keep_attributes":true}]`,
+			mockBehaviour:        func(su *mock_service.MockPerson, persons []domain.Person) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"bad input","code":"validation","errors":[{"field":"[1].Nationality","message":"must be an ISO 3166-1 alpha-2 country code"}]}`,
+		},
+		{
+			name:                 "Not A List",
+			inputBody:            `{"name":"alex", "surname":"test"}`,
+			mockBehaviour:        func(su *mock_service.MockPerson, persons []domain.Person) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"can't unpack payload","code":"validation"}`,
+		},
+		{
+			name:         "Empty Batch",
+			inputBody:    `[]`,
+			inputPersons: []domain.Person{},
+			mockBehaviour: func(su *mock_service.MockPerson, persons []domain.Person) {
+				su.EXPECT().AddBatch(gomock.Any(), persons).Return(nil, domain.ErrBatchSize)
+			},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"batch must have 1 to 100 persons","code":"validation"}`,
+		},
+		{
+			name:      "Profiler Unavailable",
+			inputBody: `[{"name":"alex", "surname":"test"}]`,
+			inputPersons: []domain.Person{
+				{Name: "alex", Surname: "test"},
+			},
+			mockBehaviour: func(su *mock_service.MockPerson, persons []domain.Person) {
+				su.EXPECT().AddBatch(gomock.Any(), persons).Return(nil, domain.NewError(domain.CodeUpstreamUnavailable, errors.New("HTTP request failed with status: 502")))
+			},
+			expectedStatusCode:   503,
+			expectedResponseBody: `{"title":"Service Unavailable","status":503,"detail":"HTTP request failed with status: 502","code":"upstream_unavailable"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			servicePerson := mock_service.NewMockPerson(c)
+			test.mockBehaviour(servicePerson, test.inputPersons)
+
+			services := &service.Service{Person: servicePerson}
+
+			validate := validator.New()
+			logger := zap.NewNop().Sugar()
+			h := NewHandler(services, validate, logger)
+
+			r := mux.NewRouter()
+			r.HandleFunc("/api/persons", h.addPersons).Methods("POST")
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/persons",
+				bytes.NewBufferString(test.inputBody))
+			req.Header.Set("Content-Type", appJSON)
+			r.ServeHTTP(w, req)
+
 			assert.Equal(t, w.Code, test.expectedStatusCode)
 			assert.Equal(t, w.Body.String(), test.expectedResponseBody)
 		})
@@ -173,28 +649,38 @@ func TestHandler_deletePerson(t *testing.T) {
 			paramID: "1",
 			inputID: 1,
 			mockBehaviour: func(su *mock_service.MockPerson, personID int) {
-				su.EXPECT().Delete(personID).Return(true, nil)
+				su.EXPECT().Delete(gomock.Any(), personID).Return(true, nil)
 			},
 			expectedStatusCode:   200,
 			expectedResponseBody: `{"status":"done"}`,
 		},
+		{
+			name:    "Not Found",
+			paramID: "1",
+			inputID: 1,
+			mockBehaviour: func(su *mock_service.MockPerson, personID int) {
+				su.EXPECT().Delete(gomock.Any(), personID).Return(false, domain.ErrPersonNotFound)
+			},
+			expectedStatusCode:   404,
+			expectedResponseBody: `{"title":"Not Found","status":404,"detail":"person not found","code":"not_found"}`,
+		},
 		{
 			name:                 "Bad ID",
 			paramID:              "1d",
 			inputID:              1,
 			mockBehaviour:        func(su *mock_service.MockPerson, personID int) {},
 			expectedStatusCode:   400,
-			expectedResponseBody: `{"message":"bad id"}`,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"bad id","code":"validation"}`,
 		},
 		{
 			name:    "Service Error",
 			paramID: "1",
 			inputID: 1,
 			mockBehaviour: func(su *mock_service.MockPerson, personID int) {
-				su.EXPECT().Delete(personID).Return(false, errors.New("something went wrong"))
+				su.EXPECT().Delete(gomock.Any(), personID).Return(false, errors.New("something went wrong"))
 			},
 			expectedStatusCode:   500,
-			expectedResponseBody: `{"message":"something went wrong"}`,
+			expectedResponseBody: `{"title":"Internal Server Error","status":500,"detail":"internal server error","code":"internal"}`,
 		},
 	}
 
@@ -247,11 +733,45 @@ func TestHandler_updatePerson(t *testing.T) {
 				Name: stringPointer("test"),
 			},
 			mockBehaviour: func(su *mock_service.MockPerson, personID int, updatePerson domain.UpdatePersonInput) {
-				su.EXPECT().Update(personID, updatePerson).Return(true, nil)
+				su.EXPECT().Update(gomock.Any(), personID, updatePerson).Return(true, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"status":"done"}`,
+		},
+		{
+			name:      "Clear patronymic",
+			inputBody: `{"patronymic":null}`,
+			paramID:   "1",
+			inputID:   1,
+			inputUpdateInput: domain.UpdatePersonInput{
+				Patronymic: domain.NewNullString(nil),
+			},
+			mockBehaviour: func(su *mock_service.MockPerson, personID int, updatePerson domain.UpdatePersonInput) {
+				su.EXPECT().Update(gomock.Any(), personID, updatePerson).Return(true, nil)
 			},
 			expectedStatusCode:   200,
 			expectedResponseBody: `{"status":"done"}`,
 		},
+		{
+			name:                 "Null name",
+			inputBody:            `{"name":null}`,
+			paramID:              "1",
+			inputID:              1,
+			inputUpdateInput:     domain.UpdatePersonInput{},
+			mockBehaviour:        func(su *mock_service.MockPerson, personID int, updatePerson domain.UpdatePersonInput) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"name: field can't be null","code":"validation"}`,
+		},
+		{
+			name:                 "Bad Attributes",
+			inputBody:            `{"age":-1,"gender":"other","nationality":"XX"}`,
+			paramID:              "1",
+			inputID:              1,
+			inputUpdateInput:     domain.UpdatePersonInput{},
+			mockBehaviour:        func(su *mock_service.MockPerson, personID int, updatePerson domain.UpdatePersonInput) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"bad attributes","code":"validation","errors":[{"field":"Age","message":"must not be negative"},{"field":"Gender","message":"must be male or female"},{"field":"Nationality","message":"must be an ISO 3166-1 alpha-2 country code"}]}`,
+		},
 		{
 			name:                 "No update values",
 			inputBody:            `{}`,
@@ -260,7 +780,21 @@ func TestHandler_updatePerson(t *testing.T) {
 			inputUpdateInput:     domain.UpdatePersonInput{},
 			mockBehaviour:        func(su *mock_service.MockPerson, personID int, updatePerson domain.UpdatePersonInput) {},
 			expectedStatusCode:   400,
-			expectedResponseBody: `{"message":"update structure has no values"}`,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"update structure has no values","code":"validation"}`,
+		},
+		{
+			name:      "Not Found",
+			inputBody: `{"name":"test"}`,
+			paramID:   "1",
+			inputID:   1,
+			inputUpdateInput: domain.UpdatePersonInput{
+				Name: stringPointer("test"),
+			},
+			mockBehaviour: func(su *mock_service.MockPerson, personID int, updatePerson domain.UpdatePersonInput) {
+				su.EXPECT().Update(gomock.Any(), personID, updatePerson).Return(false, domain.ErrPersonNotFound)
+			},
+			expectedStatusCode:   404,
+			expectedResponseBody: `{"title":"Not Found","status":404,"detail":"person not found","code":"not_found"}`,
 		},
 		{
 			name:                 "Wrong ID",
@@ -270,7 +804,7 @@ func TestHandler_updatePerson(t *testing.T) {
 			inputUpdateInput:     domain.UpdatePersonInput{},
 			mockBehaviour:        func(su *mock_service.MockPerson, personID int, updatePerson domain.UpdatePersonInput) {},
 			expectedStatusCode:   400,
-			expectedResponseBody: `{"message":"bad id"}`,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"bad id","code":"validation"}`,
 		},
 		{
 			name:      "Service Error",
@@ -281,10 +815,10 @@ func TestHandler_updatePerson(t *testing.T) {
 				Name: stringPointer("test"),
 			},
 			mockBehaviour: func(su *mock_service.MockPerson, personID int, updatePerson domain.UpdatePersonInput) {
-				su.EXPECT().Update(personID, updatePerson).Return(false, errors.New("something went wrong"))
+				su.EXPECT().Update(gomock.Any(), personID, updatePerson).Return(false, errors.New("something went wrong"))
 			},
 			expectedStatusCode:   500,
-			expectedResponseBody: `{"message":"something went wrong"}`,
+			expectedResponseBody: `{"title":"Internal Server Error","status":500,"detail":"internal server error","code":"internal"}`,
 		},
 	}
 
@@ -303,7 +837,141 @@ func TestHandler_updatePerson(t *testing.T) {
 			h := NewHandler(services, validate, logger)
 
 			r := mux.NewRouter()
-			r.HandleFunc("/api/person/{personID}", h.updatePerson).Methods("PUT")
+			r.HandleFunc("/api/person/{personID}", h.updatePerson).Methods("PATCH")
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PATCH", fmt.Sprintf("/api/person/%s", test.paramID),
+				bytes.NewBufferString(test.inputBody))
+			req.Header.Set("Content-Type", appMergePatch)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, test.expectedStatusCode)
+			assert.Equal(t, w.Body.String(), test.expectedResponseBody)
+		})
+	}
+}
+
+func TestHandler_replacePerson(t *testing.T) {
+	type mockBehaviour func(su *mock_service.MockPerson, personID int, person domain.Person)
+
+	tests := []struct {
+		name                 string
+		inputBody            string
+		paramID              string
+		inputID              int
+		inputPerson          domain.Person
+		mockBehaviour        mockBehaviour
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"name":"Dmitriy","surname":"Ushakov","age":42,"gender":"male","nationality":"RU"}`,
+			paramID:   "1",
+			inputID:   1,
+			inputPerson: domain.Person{
+				Name:        "Dmitriy",
+				Surname:     "Ushakov",
+				Age:         42,
+				Gender:      "male",
+				Nationality: "RU",
+			},
+			mockBehaviour: func(su *mock_service.MockPerson, personID int, person domain.Person) {
+				su.EXPECT().Replace(gomock.Any(), personID, person).Return(true, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"status":"done"}`,
+		},
+		{
+			name:                 "Missing fields",
+			inputBody:            `{"name":"Dmitriy","age":42,"gender":"male","nationality":"RU"}`,
+			paramID:              "1",
+			inputID:              1,
+			mockBehaviour:        func(su *mock_service.MockPerson, personID int, person domain.Person) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"bad input","code":"validation","errors":[{"field":"Surname","message":"failed on the 'required' rule"}]}`,
+		},
+		{
+			name:                 "Not enriched",
+			inputBody:            `{"name":"Dmitriy","surname":"Ushakov"}`,
+			paramID:              "1",
+			inputID:              1,
+			mockBehaviour:        func(su *mock_service.MockPerson, personID int, person domain.Person) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"person has no age, gender or nationality","code":"validation"}`,
+		},
+		{
+			name:                 "Bad Attributes",
+			inputBody:            `{"name":"Dmitriy","surname":"Ushakov","age":42,"gender":"other","nationality":"XX"}`,
+			paramID:              "1",
+			inputID:              1,
+			mockBehaviour:        func(su *mock_service.MockPerson, personID int, person domain.Person) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"bad attributes","code":"validation","errors":[{"field":"Gender","message":"must be male or female"},{"field":"Nationality","message":"must be an ISO 3166-1 alpha-2 country code"}]}`,
+		},
+		{
+			name:                 "Wrong ID",
+			inputBody:            `{"name":"Dmitriy","surname":"Ushakov","age":42,"gender":"male","nationality":"RU"}`,
+			paramID:              "1d",
+			inputID:              1,
+			mockBehaviour:        func(su *mock_service.MockPerson, personID int, person domain.Person) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"title":"Bad Request","status":400,"detail":"bad id","code":"validation"}`,
+		},
+		{
+			name:      "Not Found",
+			inputBody: `{"name":"Dmitriy","surname":"Ushakov","age":42,"gender":"male","nationality":"RU"}`,
+			paramID:   "1",
+			inputID:   1,
+			inputPerson: domain.Person{
+				Name:        "Dmitriy",
+				Surname:     "Ushakov",
+				Age:         42,
+				Gender:      "male",
+				Nationality: "RU",
+			},
+			mockBehaviour: func(su *mock_service.MockPerson, personID int, person domain.Person) {
+				su.EXPECT().Replace(gomock.Any(), personID, person).Return(false, domain.ErrPersonNotFound)
+			},
+			expectedStatusCode:   404,
+			expectedResponseBody: `{"title":"Not Found","status":404,"detail":"person not found","code":"not_found"}`,
+		},
+		{
+			name:      "Service Error",
+			inputBody: `{"name":"Dmitriy","surname":"Ushakov","age":42,"gender":"male","nationality":"RU"}`,
+			paramID:   "1",
+			inputID:   1,
+			inputPerson: domain.Person{
+				Name:        "Dmitriy",
+				Surname:     "Ushakov",
+				Age:         42,
+				Gender:      "male",
+				Nationality: "RU",
+			},
+			mockBehaviour: func(su *mock_service.MockPerson, personID int, person domain.Person) {
+				su.EXPECT().Replace(gomock.Any(), personID, person).Return(false, errors.New("something went wrong"))
+			},
+			expectedStatusCode:   500,
+			expectedResponseBody: `{"title":"Internal Server Error","status":500,"detail":"internal server error","code":"internal"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			servicePerson := mock_service.NewMockPerson(c)
+			test.mockBehaviour(servicePerson, test.inputID, test.inputPerson)
+
+			services := &service.Service{Person: servicePerson}
+
+			validate := validator.New()
+			logger := zap.NewNop().Sugar()
+			h := NewHandler(services, validate, logger)
+
+			r := mux.NewRouter()
+			r.HandleFunc("/api/person/{personID}", h.replacePerson).Methods("PUT")
 
 			w := httptest.NewRecorder()
 			req := httptest.NewRequest("PUT", fmt.Sprintf("/api/person/%s", test.paramID),
@@ -320,3 +988,7 @@ func TestHandler_updatePerson(t *testing.T) {
 func stringPointer(s string) *string {
 	return &s
 }
+
+func intPointer(i int) *int {
+	return &i
+}
@@ -2,12 +2,21 @@ package v1
 
 import (
 	"encoding/json"
+	"errors"
 	"fio/internal/domain"
+	"fmt"
 	"net/http"
+
+	"github.com/go-playground/validator"
 )
 
+// errorResponse is an RFC 7807 problem details object.
 type errorResponse struct {
-	Message string `json:"message"`
+	Title  string              `json:"title" example:"Not Found"`
+	Status int                 `json:"status" example:"404"`
+	Detail string              `json:"detail" example:"person not found"`
+	Code   domain.ErrorCode    `json:"code" example:"not_found"`
+	Errors []domain.FieldError `json:"errors,omitempty"`
 }
 
 type statusResponse struct {
@@ -18,24 +27,109 @@ type idResponse struct {
 	ID interface{} `json:"id"`
 }
 
+type idsResponse struct {
+	IDs []int `json:"ids" example:"1,2"`
+}
+
 type getPersonsResponse struct {
-	Data []domain.Person `json:"data"`
+	Data  []domain.Person `json:"data"`
+	Total int             `json:"total" example:"120"`
+	Page  int             `json:"page" example:"2"`
+	Limit int             `json:"limit" example:"25"`
+	Next  string          `json:"next,omitempty" example:"/api/persons?limit=25&page=3"`
+	Prev  string          `json:"prev,omitempty" example:"/api/persons?limit=25&page=1"`
+}
+
+type getPersonsCursorResponse struct {
+	Data        []domain.Person `json:"data"`
+	StartCursor string          `json:"start_cursor,omitempty"`
+	EndCursor   string          `json:"end_cursor,omitempty"`
+	HasNext     bool            `json:"has_next"`
+	HasPrev     bool            `json:"has_prev"`
+	Next        string          `json:"next,omitempty"`
+	Prev        string          `json:"prev,omitempty"`
+}
+
+var codeStatuses = map[domain.ErrorCode]int{
+	domain.CodeNotFound:            http.StatusNotFound,
+	domain.CodeConflict:            http.StatusConflict,
+	domain.CodeValidation:          http.StatusBadRequest,
+	domain.CodeUnauthorized:        http.StatusUnauthorized,
+	domain.CodeUpstreamUnavailable: http.StatusServiceUnavailable,
+	domain.CodeUpstreamFailed:      http.StatusBadGateway,
 }
 
-func newErrorResponse(w http.ResponseWriter, msg string, status int) {
-	resp, _ := json.Marshal(errorResponse{msg}) //nolint:errcheck
+// newErrorResponse picks the status from the error code. Errors without
+// a code are logged and reported without details.
+func (h *Handler) newErrorResponse(w http.ResponseWriter, err error) {
+	code := domain.CodeOf(err)
+	status, ok := codeStatuses[code]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	detail := err.Error()
+	if code == domain.CodeInternal {
+		h.logger.Errorf("Internal error: %s", err.Error())
+		detail = "internal server error"
+	}
+
+	resp, _ := json.Marshal(errorResponse{ //nolint:errcheck
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+		Errors: domain.FieldsOf(err),
+	})
+	w.Header().Set("Content-type", appProblemJSON)
 	w.WriteHeader(status)
 	w.Write(resp) //nolint:errcheck
 }
 
+// validationError keeps the per field details of a validator failure.
+func validationError(err error) error {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return errBadInput
+	}
+
+	fields := make([]domain.FieldError, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		fields = append(fields, domain.FieldError{
+			Field:   fieldErr.Field(),
+			Message: fmt.Sprintf("failed on the '%s' rule", fieldErr.Tag()),
+		})
+	}
+
+	return &domain.Error{Code: domain.CodeValidation, Err: errBadInput, Fields: fields}
+}
+
 func newStatusReponse(w http.ResponseWriter, msg string, status int) {
 	resp, _ := json.Marshal(statusResponse{msg}) //nolint:errcheck
 	w.WriteHeader(status)
 	w.Write(resp) //nolint:errcheck
 }
 
-func newGetPersonsResponse(w http.ResponseWriter, persons []domain.Person, status int) {
-	resp, _ := json.Marshal(getPersonsResponse{persons}) //nolint:errcheck
+func newGetPersonsResponse(w http.ResponseWriter, persons getPersonsResponse, status int) {
+	resp, _ := json.Marshal(persons) //nolint:errcheck
+	w.WriteHeader(status)
+	w.Write(resp) //nolint:errcheck
+}
+
+func newPersonResponse(w http.ResponseWriter, person domain.Person, status int) {
+	resp, _ := json.Marshal(person) //nolint:errcheck
+	w.WriteHeader(status)
+	w.Write(resp) //nolint:errcheck
+}
+
+func newGetPersonsCursorResponse(w http.ResponseWriter, persons getPersonsCursorResponse, status int) {
+	resp, _ := json.Marshal(persons) //nolint:errcheck
+	w.WriteHeader(status)
+	w.Write(resp) //nolint:errcheck
+}
+
+func newEnrichmentRunResponse(w http.ResponseWriter, run domain.EnrichmentRun, status int) {
+	resp, _ := json.Marshal(run) //nolint:errcheck
 	w.WriteHeader(status)
 	w.Write(resp) //nolint:errcheck
 }
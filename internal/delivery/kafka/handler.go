@@ -3,17 +3,26 @@ package kafka
 import (
 	"context"
 	"fio/internal/service"
+	"time"
 
 	"github.com/IBM/sarama"
 	"github.com/go-playground/validator"
 	"go.uber.org/zap"
 )
 
+// The consumer only adds persons, FioTopic is the only topic it handles.
+// Persons are updated and deleted through the REST and GraphQL APIs, which
+// report a missing person as not found.
 const (
 	FioTopic       = "FIO"
 	FioFailedTopic = "FIO_FAILED"
 )
 
+const (
+	addBatchSize = 50
+	addBatchWait = 500 * time.Millisecond
+)
+
 type MessageHandler struct {
 	services  *service.Service
 	validator *validator.Validate
@@ -48,30 +57,53 @@ func (h *MessageHandler) Setup(_ sarama.ConsumerGroupSession) error { return nil
 
 func (h *MessageHandler) Cleanup(_ sarama.ConsumerGroupSession) error { return nil }
 
+// ConsumeClaim collects the messages of FioTopic into batches of up to
+// addBatchSize, a batch is added once it is full or addBatchWait has passed.
+// Messages of other topics are skipped.
 func (h *MessageHandler) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
-	for msg := range claim.Messages() {
+	batch := make([]*sarama.ConsumerMessage, 0, addBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		h.handleAddPersonMessages(sess.Context(), batch)
+		for _, msg := range batch {
+			sess.MarkMessage(msg, "")
+		}
+		batch = batch[:0]
+	}
+
+	ticker := time.NewTicker(addBatchWait)
+	defer ticker.Stop()
+
+	for {
 		select {
-		case <-claim.Messages():
-			h.handleConsumerMessages(msg)
+		case msg, ok := <-claim.Messages():
+			if !ok {
+				flush()
+				return nil
+			}
+			h.logger.Infof("Message topic:%q partition:%d offset:%d\n", msg.Topic, msg.Partition, msg.Offset)
+
+			if msg.Topic != FioTopic {
+				sess.MarkMessage(msg, "")
+				continue
+			}
+			batch = append(batch, msg)
+			if len(batch) >= addBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
 		case <-sess.Context().Done():
 			return nil
 		}
-		h.logger.Infof("Message topic:%q partition:%d offset:%d\n", msg.Topic, msg.Partition, msg.Offset)
-
-		sess.MarkMessage(msg, "")
 	}
-	return nil
 }
 
-// TODO: refactor
-func (h *MessageHandler) handleConsumerMessages(msg *sarama.ConsumerMessage) {
-	if msg.Topic == FioTopic {
-		person, err := h.handleAddPersonMessage(msg.Value)
-		if err != nil {
-			err = h.SendPersonErrorReponseToKafka(personErrorResponse{person, errorResponse{err.Error()}}, FioFailedTopic)
-			if err != nil {
-				h.logger.Infow("failed to send message to topic", FioFailedTopic)
-			}
-		}
+// reportFailure sends resp to FioFailedTopic, a failed send is only logged.
+func (h *MessageHandler) reportFailure(resp failedMessage) {
+	if err := h.SendErrorReponseToKafka(resp, FioFailedTopic); err != nil {
+		h.logger.Infow("failed to send message to topic", FioFailedTopic)
 	}
 }
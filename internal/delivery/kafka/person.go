@@ -1,45 +1,73 @@
 package kafka
 
 import (
+	"context"
 	"encoding/json"
 	"fio/internal/domain"
 
 	"github.com/IBM/sarama"
 )
 
-func (h *MessageHandler) SendPersonErrorReponseToKafka(person personErrorResponse, topic string) error {
-	personBytes, err := json.Marshal(person)
+func (h *MessageHandler) SendErrorReponseToKafka(resp failedMessage, topic string) error {
+	respBytes, err := json.Marshal(resp)
 	if err != nil {
 		return err
 	}
 
 	msg := &sarama.ProducerMessage{
 		Topic: topic,
-		Value: sarama.ByteEncoder(personBytes),
+		Value: sarama.ByteEncoder(respBytes),
 	}
 
 	_, _, err = h.syncProducer.SendMessage(msg)
-	h.logger.Infof("Error message [%s] was sent to Kafka", person.errorResponse.Message)
+	h.logger.Infof("Error message [%s] was sent to Kafka", resp.errorMessage())
 	return err
 }
 
-func (h *MessageHandler) handleAddPersonMessage(message []byte) (domain.Person, error) {
-	var person domain.Person
-	err := json.Unmarshal(message, &person)
-	if err != nil {
-		return domain.Person{}, err
+// handleAddPersonMessages adds the persons of the messages in one batch.
+// Messages that can't be decoded or validated fail on their own.
+func (h *MessageHandler) handleAddPersonMessages(ctx context.Context, msgs []*sarama.ConsumerMessage) {
+	persons := make([]domain.Person, 0, len(msgs))
+	for _, msg := range msgs {
+		var person domain.Person
+		if err := json.Unmarshal(msg.Value, &person); err != nil {
+			h.reportFailure(personErrorResponse{domain.Person{}, newErrorResponse(err)})
+			continue
+		}
+		if err := h.validator.Struct(person); err != nil {
+			h.reportFailure(personErrorResponse{person, newErrorResponse(err)})
+			continue
+		}
+		if person.KeepAttributes {
+			if err := person.ValidateAttributes(); err != nil {
+				h.reportFailure(personErrorResponse{person, newErrorResponse(err)})
+				continue
+			}
+		}
+		persons = append(persons, person)
 	}
-
-	err = h.validator.Struct(person)
-	if err != nil {
-		return domain.Person{}, err
+	if len(persons) == 0 {
+		return
 	}
 
-	id, err := h.services.Person.Add(person)
-	if err != nil {
-		return domain.Person{}, err
+	ids, err := h.services.Person.AddBatch(ctx, persons)
+	switch {
+	case err == nil:
+		h.logger.Infof("%d persons were added", len(ids))
+	case domain.CodeOf(err) == domain.CodeConflict:
+		// The batch is added all or none, so a single duplicate would fail
+		// the others too. Add them one by one to fail only the duplicate.
+		for _, person := range persons {
+			id, err := h.services.Person.Add(ctx, person)
+			if err != nil {
+				h.reportFailure(personErrorResponse{person, newErrorResponse(err)})
+				continue
+			}
+			h.logger.Infof("Person with id %d was added", id)
+		}
+	default:
+		for _, person := range persons {
+			h.reportFailure(personErrorResponse{person, newErrorResponse(err)})
+		}
 	}
-	h.logger.Infof("Person with id %d was added", id)
-
-	return person, nil
 }
@@ -4,6 +4,24 @@ import "fio/internal/domain"
 
 type errorResponse struct {
 	Message string `json:"message"`
+	// Retryable marks failures caused by an unavailable upstream, so the
+	// message can be sent again later as is.
+	Retryable bool `json:"retryable"`
+}
+
+func newErrorResponse(err error) errorResponse {
+	return errorResponse{
+		Message:   err.Error(),
+		Retryable: domain.CodeOf(err) == domain.CodeUpstreamUnavailable,
+	}
+}
+
+func (e errorResponse) errorMessage() string {
+	return e.Message
+}
+
+type failedMessage interface {
+	errorMessage() string
 }
 
 type personErrorResponse struct {
@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strconv"
+)
+
+var ErrInvalidCursor = NewValidationError("invalid cursor")
+
+// Cursor points at a row by the values of its sort fields and its id.
+// It is handed to clients as an opaque string.
+type Cursor struct {
+	Sort   string        `json:"s,omitempty"`
+	Values []interface{} `json:"v,omitempty"`
+	ID     int           `json:"id"`
+}
+
+type CursorQuery struct {
+	After  *Cursor
+	Before *Cursor
+	Limit  int
+	// Backward takes the last Limit rows instead of the first ones.
+	Backward bool
+}
+
+type PersonsCursorQuery struct {
+	CursorQuery
+	PersonFiltersQuery
+	Sort []SortField
+}
+
+type PersonsConnection struct {
+	Persons         []Person
+	StartCursor     string
+	EndCursor       string
+	HasNextPage     bool
+	HasPreviousPage bool
+}
+
+// NewCursor builds a cursor for the person according to the sort spec.
+func NewCursor(person Person, sort []SortField) Cursor {
+	cursor := Cursor{Sort: FormatSort(sort), ID: person.ID}
+	for _, field := range sort {
+		if field.Field == "id" {
+			break
+		}
+		cursor.Values = append(cursor.Values, person.sortValue(field.Field))
+	}
+	return cursor
+}
+
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c) //nolint:errcheck
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses an opaque cursor and checks that it was issued for the same sort spec.
+func DecodeCursor(value string, sort []SortField) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err = decoder.Decode(&cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	expected := NewCursor(Person{}, sort).Values
+	if cursor.Sort != FormatSort(sort) || len(cursor.Values) != len(expected) {
+		return nil, ErrInvalidCursor
+	}
+	for i, value := range cursor.Values {
+		if !sameKind(value, expected[i]) {
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	return &cursor, nil
+}
+
+// sameKind tells whether a value decoded from a cursor can stand for the sort
+// value of a person, so a crafted cursor can't reach the database.
+func sameKind(decoded, value interface{}) bool {
+	switch value.(type) {
+	case int:
+		number, ok := decoded.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := strconv.Atoi(number.String())
+		return err == nil
+	default:
+		_, ok := decoded.(string)
+		return ok
+	}
+}
+
+// String renders the query in a stable form, so it can be used as a cache key.
+func (q PersonsCursorQuery) String() string {
+	values := url.Values{}
+	values.Set("limit", strconv.Itoa(q.Limit))
+	values.Set("backward", strconv.FormatBool(q.Backward))
+	if q.After != nil {
+		values.Set("after", q.After.Encode())
+	}
+	if q.Before != nil {
+		values.Set("before", q.Before.Encode())
+	}
+	if len(q.Sort) > 0 {
+		values.Set("sort", FormatSort(q.Sort))
+	}
+	q.PersonFiltersQuery.encode(values)
+
+	return values.Encode()
+}
+
+func (p Person) sortValue(field string) interface{} {
+	switch field {
+	case "name":
+		return p.Name
+	case "surname":
+		return p.Surname
+	case "age":
+		return p.Age
+	case "gender":
+		return p.Gender
+	case "nationality":
+		return p.Nationality
+	default:
+		return p.ID
+	}
+}
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var ErrInvalidRate = NewValidationError("rate must be a positive number")
+
+// EnrichmentStatus tells whether the profiler has filled in the attributes of
+// a person yet.
+type EnrichmentStatus string
+
+const (
+	// EnrichmentPending persons are saved but not enriched yet.
+	EnrichmentPending EnrichmentStatus = "pending"
+	// EnrichmentComplete persons have their attributes filled in.
+	EnrichmentComplete EnrichmentStatus = "complete"
+	// EnrichmentFailed persons ran out of enrichment attempts.
+	EnrichmentFailed EnrichmentStatus = "failed"
+)
+
+// EnrichmentJob is a pending person claimed for enrichment. RunID and Attempts
+// tell the claim apart from later claims of the same person.
+type EnrichmentJob struct {
+	Person
+	// RunID is the run that queued the job, nil for persons queued when added.
+	RunID *int `db:"run_id"`
+	// Attempts counts the claims of the job, the current one included.
+	Attempts int `db:"attempts"`
+	// Force overwrites the fields an operator set.
+	Force bool `db:"force"`
+}
+
+// EnrichmentRun re-enriches the persons matching its filters. The jobs of a
+// run are spread over time, so they are enriched at most Rate a second.
+type EnrichmentRun struct {
+	ID int `json:"id" db:"id" example:"1"`
+	// Filters select the persons of the run as query params, empty for all persons.
+	Filters string `json:"filters" db:"filters" example:"gender=male&nationality_in=RU"`
+	// Rate is the number of persons enriched a second.
+	Rate float64 `json:"rate" db:"rate" example:"5"`
+	// Force overwrites the fields an operator set.
+	Force bool `json:"force" db:"force" example:"false"`
+	// Total is the number of persons the run has queued.
+	Total int `json:"total" db:"total" example:"1200"`
+	// Done persons are re-enriched, Pending ones are still queued and Failed
+	// ones ran out of attempts.
+	Done      int       `json:"done" db:"-" example:"700"`
+	Pending   int       `json:"pending" db:"pending" example:"498"`
+	Failed    int       `json:"failed" db:"failed" example:"2"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Finished tells whether no person of the run is left to enrich.
+func (r EnrichmentRun) Finished() bool {
+	return r.Pending == 0
+}
+
+// Enrichment records how confident the profiler was about the attributes it
+// filled in. It is stored with the person as a single JSON document.
+type Enrichment struct {
+	Age         Confidence `json:"age"`
+	Gender      Confidence `json:"gender"`
+	Nationality Confidence `json:"nationality"`
+}
+
+// Confidence describes a single enriched attribute.
+type Confidence struct {
+	// Source names the provider of the answer, e.g. remote or morphology.
+	Source string `json:"source,omitempty" example:"remote"`
+	// Probability of the chosen value, nil if the upstream doesn't report one.
+	Probability *float64 `json:"probability,omitempty" example:"0.98"`
+	// Count is the number of samples behind the answer.
+	Count int `json:"count" example:"1204"`
+	// Alternatives are the values that were not chosen, the most likely first.
+	Alternatives []Alternative `json:"alternatives,omitempty"`
+}
+
+type Alternative struct {
+	Value       string  `json:"value" example:"UA"`
+	Probability float64 `json:"probability" example:"0.12"`
+}
+
+// Value stores the enrichment as JSON.
+func (e Enrichment) Value() (driver.Value, error) {
+	return json.Marshal(e)
+}
+
+// Scan reads the enrichment from JSON.
+func (e *Enrichment) Scan(src interface{}) error {
+	switch data := src.(type) {
+	case []byte:
+		return json.Unmarshal(data, e)
+	case string:
+		return json.Unmarshal([]byte(data), e)
+	}
+	return errors.New("enrichment: unsupported type")
+}
@@ -2,6 +2,67 @@ package domain
 
 import "errors"
 
+// ErrorCode classifies an error, so delivery layers can pick a status for it
+// without knowing where the error came from.
+type ErrorCode string
+
+const (
+	CodeInternal            ErrorCode = "internal"
+	CodeNotFound            ErrorCode = "not_found"
+	CodeConflict            ErrorCode = "conflict"
+	CodeValidation          ErrorCode = "validation"
+	CodeUnauthorized        ErrorCode = "unauthorized"
+	CodeUpstreamUnavailable ErrorCode = "upstream_unavailable"
+	CodeUpstreamFailed      ErrorCode = "upstream_failed"
+)
+
 var (
-	ErrPersonNotFound = errors.New("person not found")
+	ErrPersonNotFound        = NewError(CodeNotFound, errors.New("person not found"))
+	ErrEnrichmentRunNotFound = NewError(CodeNotFound, errors.New("enrichment run not found"))
 )
+
+// FieldError describes why a single input field was rejected.
+type FieldError struct {
+	Field   string `json:"field" example:"name"`
+	Message string `json:"message" example:"failed on the 'required' rule"`
+}
+
+type Error struct {
+	Code   ErrorCode
+	Err    error
+	Fields []FieldError
+}
+
+func NewError(code ErrorCode, err error) *Error {
+	return &Error{Code: code, Err: err}
+}
+
+func NewValidationError(msg string) *Error {
+	return NewError(CodeValidation, errors.New(msg))
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// CodeOf returns the code of the first Error in the chain, CodeInternal if there is none.
+func CodeOf(err error) ErrorCode {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Code
+	}
+	return CodeInternal
+}
+
+// FieldsOf returns the field details of the first Error in the chain.
+func FieldsOf(err error) []FieldError {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Fields
+	}
+	return nil
+}
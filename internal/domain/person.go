@@ -1,12 +1,28 @@
 package domain
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
+	"fmt"
 )
 
+// MaxBatchSize is the number of persons a single batch may add.
+const MaxBatchSize = 100
+
 var (
 	ErrNoOptions      = errors.New("no options")
-	ErrUpdateNoFields = errors.New("update structure has no values")
+	ErrUpdateNoFields = NewValidationError("update structure has no values")
+	ErrNullField      = NewValidationError("field can't be null")
+	ErrIncomplete     = NewValidationError("person has no age, gender or nationality")
+	ErrBatchSize      = NewValidationError(fmt.Sprintf("batch must have 1 to %d persons", MaxBatchSize))
+	ErrBadAttributes  = errors.New("bad attributes")
+)
+
+// Genders a person may have.
+const (
+	GenderMale   = "male"
+	GenderFemale = "female"
 )
 
 type Person struct {
@@ -17,21 +33,135 @@ type Person struct {
 	Age         int     `json:"age" db:"age" schema:"age" example:"22"`
 	Gender      string  `json:"gender" db:"gender" schema:"gender" example:"male"`
 	Nationality string  `json:"nationality" db:"nationality" schema:"nationality"`
+	// Enrichment is filled in by the service, it is ignored on input.
+	Enrichment *Enrichment `json:"enrichment,omitempty" db:"enrichment" schema:"-"`
+	// EnrichmentStatus is set by the service, it is ignored on input.
+	EnrichmentStatus EnrichmentStatus `json:"enrichment_status,omitempty" db:"enrichment_status" schema:"-" enums:"pending,complete,failed" example:"complete"`
+	// Provenance is set by the service, it is ignored on input.
+	Provenance *Provenance `json:"provenance,omitempty" db:"provenance" schema:"-"`
+	// KeepAttributes makes a new person keep the age, gender and nationality
+	// it was given, only the missing ones are enriched.
+	KeepAttributes bool `json:"keep_attributes,omitempty" db:"-" schema:"-"`
+}
+
+// Validate checks the fields that are otherwise filled in by enrichment,
+// so a full replacement can't leave them empty.
+func (p Person) Validate() error {
+	if p.Age <= 0 || p.Gender == "" || p.Nationality == "" {
+		return ErrIncomplete
+	}
+
+	return nil
+}
+
+// ValidateAttributes checks the age, gender and nationality a client gave,
+// the zero ones are missing and left to enrichment.
+func (p Person) ValidateAttributes() error {
+	var fields []FieldError
+	if p.Age < 0 {
+		fields = append(fields, FieldError{Field: "Age", Message: "must not be negative"})
+	}
+	if p.Gender != "" && p.Gender != GenderMale && p.Gender != GenderFemale {
+		fields = append(fields, FieldError{Field: "Gender", Message: fmt.Sprintf("must be %s or %s", GenderMale, GenderFemale)})
+	}
+	if p.Nationality != "" && !IsCountryCode(p.Nationality) {
+		fields = append(fields, FieldError{Field: "Nationality", Message: "must be an ISO 3166-1 alpha-2 country code"})
+	}
+	if len(fields) > 0 {
+		return &Error{Code: CodeValidation, Err: ErrBadAttributes, Fields: fields}
+	}
+
+	return nil
 }
 
 type UpdatePersonInput struct {
-	Name        *string `json:"name" db:"name" example:"Alexey"`
-	Surname     *string `json:"surname" db:"surname" example:"Yakovlev"`
-	Patronymic  *string `json:"patronymic" db:"patronymic" example:"Vladimirovich"`
-	Age         *int    `json:"age" db:"age" example:"22"`
-	Gender      *string `json:"gender" db:"gender" example:"male"`
-	Nationality *string `json:"nationality" db:"nationality" example:"RU"`
+	Name        *string    `json:"name" db:"name" example:"Alexey"`
+	Surname     *string    `json:"surname" db:"surname" example:"Yakovlev"`
+	Patronymic  NullString `json:"patronymic" db:"patronymic" swaggertype:"string" example:"Vladimirovich"`
+	Age         *int       `json:"age" db:"age" example:"22"`
+	Gender      *string    `json:"gender" db:"gender" example:"male"`
+	Nationality *string    `json:"nationality" db:"nationality" example:"RU"`
+	// Provenance of the fields set by the update, filled in by the service.
+	Provenance *Provenance `json:"-" db:"-" swaggerignore:"true"`
+}
+
+// nonNullableFields can be omitted from a patch but can't be set to null.
+var nonNullableFields = []string{"name", "surname", "age", "gender", "nationality"}
+
+// UnmarshalJSON decodes the input as a JSON Merge Patch (RFC 7396):
+// an omitted field is left as is, null clears it.
+func (i *UpdatePersonInput) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for _, field := range nonNullableFields {
+		if value, ok := fields[field]; ok && bytes.Equal(value, []byte("null")) {
+			return fmt.Errorf("%s: %w", field, ErrNullField)
+		}
+	}
+
+	type input UpdatePersonInput
+	return json.Unmarshal(data, (*input)(i))
 }
 
 func (i UpdatePersonInput) Validate() error {
-	if i.Name == nil && i.Surname == nil && i.Patronymic == nil && i.Age == nil && i.Gender == nil && i.Nationality == nil {
+	if i.Name == nil && i.Surname == nil && !i.Patronymic.Set && i.Age == nil && i.Gender == nil && i.Nationality == nil {
 		return ErrUpdateNoFields
 	}
 
 	return nil
 }
+
+// ValidateAttributes checks the age, gender and nationality the update sets
+// the same way Person.ValidateAttributes does.
+func (i UpdatePersonInput) ValidateAttributes() error {
+	var person Person
+	if i.Age != nil {
+		person.Age = *i.Age
+	}
+	if i.Gender != nil {
+		person.Gender = *i.Gender
+	}
+	if i.Nationality != nil {
+		person.Nationality = *i.Nationality
+	}
+	return person.ValidateAttributes()
+}
+
+// NullString tells an omitted value apart from an explicit null.
+type NullString struct {
+	String string
+	// Valid is false for null.
+	Valid bool
+	// Set is true when the value was present in the input.
+	Set bool
+}
+
+func NewNullString(value *string) NullString {
+	if value == nil {
+		return NullString{Set: true}
+	}
+	return NullString{String: *value, Valid: true, Set: true}
+}
+
+// Ptr returns the value as a pointer, nil for null.
+func (s NullString) Ptr() *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
+
+func (s *NullString) UnmarshalJSON(data []byte) error {
+	var value *string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*s = NewNullString(value)
+	return nil
+}
+
+func (s NullString) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Ptr())
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Sources of a field value.
+const (
+	// ProvenanceManual values were set by an operator or given by the client
+	// that added the person, enrichment leaves them alone unless it is forced.
+	ProvenanceManual = "manual"
+	// ProvenanceEnriched values were filled in by the profiler.
+	ProvenanceEnriched = "enriched"
+)
+
+// Provenance tells where the values of the enriched fields of a person came
+// from. A field without provenance was never set by either. It is stored with
+// the person as a single JSON document.
+type Provenance struct {
+	Age         *FieldProvenance `json:"age,omitempty"`
+	Gender      *FieldProvenance `json:"gender,omitempty"`
+	Nationality *FieldProvenance `json:"nationality,omitempty"`
+}
+
+type FieldProvenance struct {
+	// Source is manual or enriched.
+	Source    string    `json:"source" enums:"manual,enriched" example:"manual"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewManualProvenance marks the fields set by hand at the given time.
+func NewManualProvenance(age, gender, nationality bool, at time.Time) *Provenance {
+	field := func(set bool) *FieldProvenance {
+		if !set {
+			return nil
+		}
+		return &FieldProvenance{Source: ProvenanceManual, UpdatedAt: at}
+	}
+	return &Provenance{Age: field(age), Gender: field(gender), Nationality: field(nationality)}
+}
+
+// IsManual tells whether the field was set by hand, nil is not.
+func (f *FieldProvenance) IsManual() bool {
+	return f != nil && f.Source == ProvenanceManual
+}
+
+// Value stores the provenance as JSON.
+func (p Provenance) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+// Scan reads the provenance from JSON.
+func (p *Provenance) Scan(src interface{}) error {
+	switch data := src.(type) {
+	case []byte:
+		return json.Unmarshal(data, p)
+	case string:
+		return json.Unmarshal([]byte(data), p)
+	}
+	return errors.New("provenance: unsupported type")
+}
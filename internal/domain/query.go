@@ -1,6 +1,20 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/schema"
+)
+
+var (
+	ErrInvalidSort      = NewValidationError("invalid sort field")
+	ErrInvalidAgeRange  = NewValidationError("age_min is greater than age_max")
+	ErrInvalidMatchMode = NewValidationError("invalid match mode")
+	ErrBadQuery         = NewValidationError("bad query")
+)
 
 type PaginationKey string
 
@@ -11,18 +25,212 @@ type PaginationQuery struct {
 	Offset int
 }
 
+// Page returns the 1-based page number the offset points at.
+func (p PaginationQuery) Page() int {
+	if p.Limit <= 0 {
+		return 1
+	}
+	return p.Offset/p.Limit + 1
+}
+
 type PersonFiltersQuery struct {
-	Name        *string `schema:"name" example:"Vladimir"`
-	Surname     *string `schema:"surname" example:"Davydov"`
-	Patronymic  *string `schema:"patronymic" example:"Viktorovych"`
-	Age         *int    `schema:"age" example:"35"`
-	Gender      *string `schema:"gender" example:"male"`
-	Nationality *string `schema:"nationality" example:"RU"`
+	Name             *string  `schema:"name" example:"Vladimir"`
+	Surname          *string  `schema:"surname" example:"Davydov"`
+	Patronymic       *string  `schema:"patronymic" example:"Viktorovych"`
+	Age              *int     `schema:"age" example:"35"`
+	Gender           *string  `schema:"gender" example:"male"`
+	Nationality      *string  `schema:"nationality" example:"RU"`
+	AgeMin           *int     `schema:"age_min" form:"age_min" example:"18"`
+	AgeMax           *int     `schema:"age_max" form:"age_max" example:"65"`
+	GenderIn         []string `schema:"gender_in" form:"gender_in" example:"male,female"`
+	NationalityIn    []string `schema:"nationality_in" form:"nationality_in" example:"RU,UA"`
+	PatronymicIsNull *bool    `schema:"patronymic_is_null" form:"patronymic_is_null" example:"true"`
+	// Q searches across name, surname and patronymic, results are ranked by relevance.
+	Q *string `schema:"q" form:"q" example:"dmitriy ushakov"`
+	// Match selects how name, surname and patronymic are compared, exact by default.
+	Match MatchMode `schema:"match" form:"match" enums:"exact,iexact,prefix,contains" example:"prefix"`
+}
+
+// ParsePersonFilters decodes and validates filters given as query params.
+// Lists are accepted both as repeated params and comma separated values.
+func ParsePersonFilters(values url.Values) (PersonFiltersQuery, error) {
+	var filters PersonFiltersQuery
+	decoder := schema.NewDecoder()
+	decoder.IgnoreUnknownKeys(true)
+	if err := decoder.Decode(&filters, values); err != nil {
+		return PersonFiltersQuery{}, ErrBadQuery
+	}
+	filters.GenderIn = splitList(filters.GenderIn)
+	filters.NationalityIn = splitList(filters.NationalityIn)
+
+	if err := filters.Validate(); err != nil {
+		return PersonFiltersQuery{}, err
+	}
+	return filters, nil
+}
+
+// splitList accepts both repeated query params and comma separated values.
+func splitList(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				result = append(result, item)
+			}
+		}
+	}
+	return result
+}
+
+// String renders the filters in a stable form, so they can be used as a cache key.
+func (f PersonFiltersQuery) String() string {
+	values := url.Values{}
+	f.encode(values)
+	return values.Encode()
+}
+
+func (f PersonFiltersQuery) encode(values url.Values) {
+	setString := func(key string, value *string) {
+		if value != nil {
+			values.Set(key, *value)
+		}
+	}
+	setString("name", f.Name)
+	setString("surname", f.Surname)
+	setString("patronymic", f.Patronymic)
+	setString("gender", f.Gender)
+	setString("nationality", f.Nationality)
+	setString("q", f.Q)
+
+	setInt := func(key string, value *int) {
+		if value != nil {
+			values.Set(key, strconv.Itoa(*value))
+		}
+	}
+	setInt("age", f.Age)
+	setInt("age_min", f.AgeMin)
+	setInt("age_max", f.AgeMax)
+	for _, gender := range f.GenderIn {
+		values.Add("gender_in", gender)
+	}
+	for _, nationality := range f.NationalityIn {
+		values.Add("nationality_in", nationality)
+	}
+	if f.PatronymicIsNull != nil {
+		values.Set("patronymic_is_null", strconv.FormatBool(*f.PatronymicIsNull))
+	}
+	if f.Match != "" {
+		values.Set("match", string(f.Match))
+	}
+}
+
+type MatchMode string
+
+const (
+	MatchExact    MatchMode = "exact"
+	MatchIExact   MatchMode = "iexact"
+	MatchPrefix   MatchMode = "prefix"
+	MatchContains MatchMode = "contains"
+)
+
+func (m MatchMode) Validate() error {
+	switch m {
+	case "", MatchExact, MatchIExact, MatchPrefix, MatchContains:
+		return nil
+	}
+	return ErrInvalidMatchMode
+}
+
+func (f PersonFiltersQuery) Validate() error {
+	if f.AgeMin != nil && f.AgeMax != nil && *f.AgeMin > *f.AgeMax {
+		return ErrInvalidAgeRange
+	}
+
+	return f.Match.Validate()
+}
+
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// sortableFields whitelists the person fields that can be used for ordering.
+var sortableFields = map[string]struct{}{
+	"id":          {},
+	"name":        {},
+	"surname":     {},
+	"age":         {},
+	"gender":      {},
+	"nationality": {},
+}
+
+// IsSortable tells whether persons can be ordered by the field, a sortable
+// field is named as its column.
+func IsSortable(field string) bool {
+	_, ok := sortableFields[field]
+	return ok
 }
 
 type PersonsQuery struct {
 	PaginationQuery
 	PersonFiltersQuery
+	Sort []SortField
+}
+
+// String renders the query in a stable form, so it can be used as a cache key.
+func (q PersonsQuery) String() string {
+	values := url.Values{}
+	values.Set("limit", strconv.Itoa(q.Limit))
+	values.Set("offset", strconv.Itoa(q.Offset))
+	if len(q.Sort) > 0 {
+		values.Set("sort", FormatSort(q.Sort))
+	}
+
+	q.PersonFiltersQuery.encode(values)
+
+	return values.Encode()
+}
+
+// ParseSort parses a comma separated sort spec like "-age,surname",
+// where a leading minus means descending order.
+func ParseSort(spec string) ([]SortField, error) {
+	if spec == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(spec, ",")
+	fields := make([]SortField, 0, len(parts))
+	seen := make(map[string]struct{}, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		field := SortField{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+
+		if !IsSortable(field.Field) {
+			return nil, ErrInvalidSort
+		}
+		if _, ok := seen[field.Field]; ok {
+			return nil, ErrInvalidSort
+		}
+		seen[field.Field] = struct{}{}
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+func FormatSort(fields []SortField) string {
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field.Desc {
+			parts = append(parts, "-"+field.Field)
+		} else {
+			parts = append(parts, field.Field)
+		}
+	}
+	return strings.Join(parts, ",")
 }
 
 func PaginationFromContext(ctx context.Context) (*PaginationQuery, error) {
@@ -5,8 +5,10 @@
 package mock_repository
 
 import (
+	context "context"
 	domain "fio/internal/domain"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -35,61 +37,237 @@ func (m *MockPersonRepo) EXPECT() *MockPersonRepoMockRecorder {
 }
 
 // Add mocks base method.
-func (m *MockPersonRepo) Add(person domain.Person) (int, error) {
+func (m *MockPersonRepo) Add(ctx context.Context, person domain.Person) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Add", person)
+	ret := m.ctrl.Call(m, "Add", ctx, person)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Add indicates an expected call of Add.
-func (mr *MockPersonRepoMockRecorder) Add(person interface{}) *gomock.Call {
+func (mr *MockPersonRepoMockRecorder) Add(ctx, person interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockPersonRepo)(nil).Add), person)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockPersonRepo)(nil).Add), ctx, person)
+}
+
+// AddBatch mocks base method.
+func (m *MockPersonRepo) AddBatch(ctx context.Context, persons []domain.Person) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddBatch", ctx, persons)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddBatch indicates an expected call of AddBatch.
+func (mr *MockPersonRepoMockRecorder) AddBatch(ctx, persons interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBatch", reflect.TypeOf((*MockPersonRepo)(nil).AddBatch), ctx, persons)
+}
+
+// ClaimEnrichment mocks base method.
+func (m *MockPersonRepo) ClaimEnrichment(ctx context.Context, limit int, lease time.Duration) ([]domain.EnrichmentJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimEnrichment", ctx, limit, lease)
+	ret0, _ := ret[0].([]domain.EnrichmentJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimEnrichment indicates an expected call of ClaimEnrichment.
+func (mr *MockPersonRepoMockRecorder) ClaimEnrichment(ctx, limit, lease interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimEnrichment", reflect.TypeOf((*MockPersonRepo)(nil).ClaimEnrichment), ctx, limit, lease)
+}
+
+// CompleteEnrichment mocks base method.
+func (m *MockPersonRepo) CompleteEnrichment(ctx context.Context, job domain.EnrichmentJob) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteEnrichment", ctx, job)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteEnrichment indicates an expected call of CompleteEnrichment.
+func (mr *MockPersonRepoMockRecorder) CompleteEnrichment(ctx, job interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteEnrichment", reflect.TypeOf((*MockPersonRepo)(nil).CompleteEnrichment), ctx, job)
+}
+
+// Count mocks base method.
+func (m *MockPersonRepo) Count(ctx context.Context, filters domain.PersonFiltersQuery) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", ctx, filters)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockPersonRepoMockRecorder) Count(ctx, filters interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockPersonRepo)(nil).Count), ctx, filters)
+}
+
+// CreateEnrichmentRun mocks base method.
+func (m *MockPersonRepo) CreateEnrichmentRun(ctx context.Context, filters domain.PersonFiltersQuery, rate float64, force bool) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEnrichmentRun", ctx, filters, rate, force)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateEnrichmentRun indicates an expected call of CreateEnrichmentRun.
+func (mr *MockPersonRepoMockRecorder) CreateEnrichmentRun(ctx, filters, rate, force interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEnrichmentRun", reflect.TypeOf((*MockPersonRepo)(nil).CreateEnrichmentRun), ctx, filters, rate, force)
 }
 
 // Delete mocks base method.
-func (m *MockPersonRepo) Delete(personID int) (bool, error) {
+func (m *MockPersonRepo) Delete(ctx context.Context, personID int) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", personID)
+	ret := m.ctrl.Call(m, "Delete", ctx, personID)
 	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockPersonRepoMockRecorder) Delete(personID interface{}) *gomock.Call {
+func (mr *MockPersonRepoMockRecorder) Delete(ctx, personID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockPersonRepo)(nil).Delete), ctx, personID)
+}
+
+// FailEnrichment mocks base method.
+func (m *MockPersonRepo) FailEnrichment(ctx context.Context, job domain.EnrichmentJob, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailEnrichment", ctx, job, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailEnrichment indicates an expected call of FailEnrichment.
+func (mr *MockPersonRepoMockRecorder) FailEnrichment(ctx, job, reason interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockPersonRepo)(nil).Delete), personID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailEnrichment", reflect.TypeOf((*MockPersonRepo)(nil).FailEnrichment), ctx, job, reason)
 }
 
 // GetAll mocks base method.
-func (m *MockPersonRepo) GetAll(opts domain.PersonsQuery) ([]domain.Person, error) {
+func (m *MockPersonRepo) GetAll(ctx context.Context, opts domain.PersonsQuery) ([]domain.Person, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll", opts)
+	ret := m.ctrl.Call(m, "GetAll", ctx, opts)
 	ret0, _ := ret[0].([]domain.Person)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAll indicates an expected call of GetAll.
-func (mr *MockPersonRepoMockRecorder) GetAll(opts interface{}) *gomock.Call {
+func (mr *MockPersonRepoMockRecorder) GetAll(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockPersonRepo)(nil).GetAll), ctx, opts)
+}
+
+// GetAllByCursor mocks base method.
+func (m *MockPersonRepo) GetAllByCursor(ctx context.Context, opts domain.PersonsCursorQuery) ([]domain.Person, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllByCursor", ctx, opts)
+	ret0, _ := ret[0].([]domain.Person)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllByCursor indicates an expected call of GetAllByCursor.
+func (mr *MockPersonRepoMockRecorder) GetAllByCursor(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByCursor", reflect.TypeOf((*MockPersonRepo)(nil).GetAllByCursor), ctx, opts)
+}
+
+// GetByID mocks base method.
+func (m *MockPersonRepo) GetByID(ctx context.Context, personID int) (domain.Person, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, personID)
+	ret0, _ := ret[0].(domain.Person)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockPersonRepoMockRecorder) GetByID(ctx, personID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockPersonRepo)(nil).GetByID), ctx, personID)
+}
+
+// GetEnrichmentRun mocks base method.
+func (m *MockPersonRepo) GetEnrichmentRun(ctx context.Context, runID int) (domain.EnrichmentRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEnrichmentRun", ctx, runID)
+	ret0, _ := ret[0].(domain.EnrichmentRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEnrichmentRun indicates an expected call of GetEnrichmentRun.
+func (mr *MockPersonRepoMockRecorder) GetEnrichmentRun(ctx, runID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEnrichmentRun", reflect.TypeOf((*MockPersonRepo)(nil).GetEnrichmentRun), ctx, runID)
+}
+
+// Replace mocks base method.
+func (m *MockPersonRepo) Replace(ctx context.Context, personID int, person domain.Person) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Replace", ctx, personID, person)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Replace indicates an expected call of Replace.
+func (mr *MockPersonRepoMockRecorder) Replace(ctx, personID, person interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Replace", reflect.TypeOf((*MockPersonRepo)(nil).Replace), ctx, personID, person)
+}
+
+// RetryEnrichment mocks base method.
+func (m *MockPersonRepo) RetryEnrichment(ctx context.Context, job domain.EnrichmentJob, runAt time.Time, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryEnrichment", ctx, job, runAt, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetryEnrichment indicates an expected call of RetryEnrichment.
+func (mr *MockPersonRepoMockRecorder) RetryEnrichment(ctx, job, runAt, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryEnrichment", reflect.TypeOf((*MockPersonRepo)(nil).RetryEnrichment), ctx, job, runAt, reason)
+}
+
+// ScheduleEnrichmentRun mocks base method.
+func (m *MockPersonRepo) ScheduleEnrichmentRun(ctx context.Context, runID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduleEnrichmentRun", ctx, runID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ScheduleEnrichmentRun indicates an expected call of ScheduleEnrichmentRun.
+func (mr *MockPersonRepoMockRecorder) ScheduleEnrichmentRun(ctx, runID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockPersonRepo)(nil).GetAll), opts)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleEnrichmentRun", reflect.TypeOf((*MockPersonRepo)(nil).ScheduleEnrichmentRun), ctx, runID)
 }
 
 // Update mocks base method.
-func (m *MockPersonRepo) Update(personID int, input domain.UpdatePersonInput) (bool, error) {
+func (m *MockPersonRepo) Update(ctx context.Context, personID int, input domain.UpdatePersonInput) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", personID, input)
+	ret := m.ctrl.Call(m, "Update", ctx, personID, input)
 	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockPersonRepoMockRecorder) Update(personID, input interface{}) *gomock.Call {
+func (mr *MockPersonRepoMockRecorder) Update(ctx, personID, input interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockPersonRepo)(nil).Update), personID, input)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockPersonRepo)(nil).Update), ctx, personID, input)
 }
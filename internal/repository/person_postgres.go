@@ -1,14 +1,21 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"fio/internal/domain"
 	"fio/pkg/database/postgres"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+// fullNameExpr must match the expression of persons_full_name_trgm_idx.
+const fullNameExpr = "(name || ' ' || surname || ' ' || coalesce(patronymic, ''))"
+
 type PersonPostgresqlRepository struct {
 	db *sqlx.DB
 }
@@ -17,88 +24,476 @@ func NewPersonPostgresqlRepository(db *sqlx.DB) *PersonPostgresqlRepository {
 	return &PersonPostgresqlRepository{db: db}
 }
 
-func (repo *PersonPostgresqlRepository) GetAll(opts domain.PersonsQuery) ([]domain.Person, error) {
+func (repo *PersonPostgresqlRepository) GetAll(ctx context.Context, opts domain.PersonsQuery) ([]domain.Person, error) {
 	var persons []domain.Person
+
+	conValues, args := filterConditions(opts.PersonFiltersQuery)
+	orderBy := orderByClause(opts.Sort)
+	if q := searchQuery(opts.PersonFiltersQuery); q != "" && len(opts.Sort) == 0 {
+		args = append(args, q)
+		orderBy = fmt.Sprintf("word_similarity($%d, %s) DESC, %s", len(args), fullNameExpr, orderBy)
+	}
+
+	argID := len(args) + 1
+	args = append(args, opts.PaginationQuery.Limit, opts.PaginationQuery.Offset)
+
+	conQuery := strings.Join(conValues, " AND ")
+
+	query := fmt.Sprintf("SELECT * FROM %s ORDER BY %s LIMIT $%d OFFSET $%d", personsTable, orderBy, argID, argID+1)
+	if len(conValues) > 0 {
+		query = fmt.Sprintf("SELECT * FROM %s WHERE %s ORDER BY %s LIMIT $%d OFFSET $%d", personsTable, conQuery, orderBy, argID, argID+1)
+	}
+
+	if err := repo.db.SelectContext(ctx, &persons, query, args...); err != nil {
+		return []domain.Person{}, parsePostgresError(err)
+	}
+
+	return persons, nil
+}
+
+func (repo *PersonPostgresqlRepository) Count(ctx context.Context, filters domain.PersonFiltersQuery) (int, error) {
+	var count int
+
+	conValues, args := filterConditions(filters)
+
+	query := fmt.Sprintf("SELECT count(*) FROM %s", personsTable)
+	if len(conValues) > 0 {
+		query = fmt.Sprintf("SELECT count(*) FROM %s WHERE %s", personsTable, strings.Join(conValues, " AND "))
+	}
+
+	if err := repo.db.GetContext(ctx, &count, query, args...); err != nil {
+		return 0, parsePostgresError(err)
+	}
+
+	return count, nil
+}
+
+// filterConditions translates filters into parameterised WHERE conditions,
+// numbering placeholders from $1.
+func filterConditions(filters domain.PersonFiltersQuery) ([]string, []interface{}) {
 	conValues := make([]string, 0)
 	args := make([]interface{}, 0)
-	argID := 1
 
-	if opts.PersonFiltersQuery.Age != nil {
-		conValues = append(conValues, fmt.Sprintf("age=$%d", argID))
-		args = append(args, *opts.PersonFiltersQuery.Age)
-		argID++
+	addCondition := func(format string, arg interface{}) {
+		args = append(args, arg)
+		conValues = append(conValues, fmt.Sprintf(format, len(args)))
 	}
 
-	if opts.PersonFiltersQuery.Gender != nil {
-		conValues = append(conValues, fmt.Sprintf("gender=$%d", argID))
-		args = append(args, *opts.PersonFiltersQuery.Gender)
-		argID++
+	if filters.Age != nil {
+		addCondition("age=$%d", *filters.Age)
 	}
 
-	if opts.PersonFiltersQuery.Name != nil {
-		conValues = append(conValues, fmt.Sprintf("name=$%d", argID))
-		args = append(args, *opts.PersonFiltersQuery.Name)
-		argID++
+	if filters.Gender != nil {
+		addCondition("gender=$%d", *filters.Gender)
 	}
 
-	if opts.PersonFiltersQuery.Nationality != nil {
-		conValues = append(conValues, fmt.Sprintf("nationality=$%d", argID))
-		args = append(args, *opts.PersonFiltersQuery.Nationality)
-		argID++
+	if filters.Name != nil {
+		addCondition(textCondition("name", *filters.Name, filters.Match))
 	}
 
-	if opts.PersonFiltersQuery.Patronymic != nil {
-		conValues = append(conValues, fmt.Sprintf("patronymic=$%d", argID))
-		args = append(args, *opts.PersonFiltersQuery.Patronymic)
-		argID++
+	if filters.Nationality != nil {
+		addCondition("nationality=$%d", *filters.Nationality)
 	}
 
-	if opts.PersonFiltersQuery.Surname != nil {
-		conValues = append(conValues, fmt.Sprintf("surname=$%d", argID))
-		args = append(args, *opts.PersonFiltersQuery.Surname)
-		argID++
+	if filters.Patronymic != nil {
+		addCondition(textCondition("patronymic", *filters.Patronymic, filters.Match))
 	}
-	args = append(args, opts.PaginationQuery.Limit, opts.PaginationQuery.Offset)
 
-	conQuery := strings.Join(conValues, " AND ")
+	if filters.Surname != nil {
+		addCondition(textCondition("surname", *filters.Surname, filters.Match))
+	}
+
+	if filters.AgeMin != nil {
+		addCondition("age>=$%d", *filters.AgeMin)
+	}
+
+	if filters.AgeMax != nil {
+		addCondition("age<=$%d", *filters.AgeMax)
+	}
+
+	if len(filters.GenderIn) > 0 {
+		addCondition("gender=ANY($%d)", pq.Array(filters.GenderIn))
+	}
+
+	if len(filters.NationalityIn) > 0 {
+		addCondition("nationality=ANY($%d)", pq.Array(filters.NationalityIn))
+	}
+
+	if q := searchQuery(filters); q != "" {
+		addCondition("$%d <%% "+fullNameExpr, q)
+	}
+
+	if filters.PatronymicIsNull != nil {
+		if *filters.PatronymicIsNull {
+			conValues = append(conValues, "patronymic IS NULL")
+		} else {
+			conValues = append(conValues, "patronymic IS NOT NULL")
+		}
+	}
+
+	return conValues, args
+}
+
+func searchQuery(filters domain.PersonFiltersQuery) string {
+	if filters.Q == nil {
+		return ""
+	}
+	return strings.TrimSpace(*filters.Q)
+}
+
+// textCondition returns a condition format and its argument for a text
+// column compared according to the match mode.
+func textCondition(column, value string, match domain.MatchMode) (string, interface{}) {
+	switch match {
+	case domain.MatchIExact:
+		return column + " ILIKE $%d", escapeLike(value)
+	case domain.MatchPrefix:
+		return column + " ILIKE $%d", escapeLike(value) + "%"
+	case domain.MatchContains:
+		return column + " ILIKE $%d", "%" + escapeLike(value) + "%"
+	default:
+		return column + "=$%d", value
+	}
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike escapes LIKE wildcards, so user input is matched literally.
+func escapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
+
+// orderColumns keeps the fields of the sort spec the domain allows sorting
+// by, they are named as their columns. It always ends with id, so that pages
+// are stable between calls.
+func orderColumns(sort []domain.SortField) []domain.SortField {
+	columns := make([]domain.SortField, 0, len(sort)+1)
+	for _, field := range sort {
+		if !domain.IsSortable(field.Field) {
+			continue
+		}
+		columns = append(columns, field)
+		if field.Field == "id" {
+			return columns
+		}
+	}
+
+	return append(columns, domain.SortField{Field: "id"})
+}
+
+func orderByClause(sort []domain.SortField) string {
+	return joinOrder(orderColumns(sort), false)
+}
+
+func joinOrder(columns []domain.SortField, reverse bool) string {
+	orderValues := make([]string, 0, len(columns))
+	for _, column := range columns {
+		direction := "ASC"
+		if column.Desc != reverse {
+			direction = "DESC"
+		}
+		orderValues = append(orderValues, fmt.Sprintf("%s %s", column.Field, direction))
+	}
+
+	return strings.Join(orderValues, ", ")
+}
+
+// keysetCondition selects rows strictly after (or before) the cursor in the
+// order given by columns, numbering placeholders from argID.
+func keysetCondition(columns []domain.SortField, cursor *domain.Cursor, before bool, argID int) (string, []interface{}) {
+	args := append(append(make([]interface{}, 0, len(columns)), cursor.Values...), cursor.ID)
+
+	orValues := make([]string, 0, len(columns))
+	for i, column := range columns {
+		andValues := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			andValues = append(andValues, fmt.Sprintf("%s=$%d", columns[j].Field, argID+j))
+		}
+		operator := ">"
+		if column.Desc != before {
+			operator = "<"
+		}
+		andValues = append(andValues, fmt.Sprintf("%s%s$%d", column.Field, operator, argID+i))
+		orValues = append(orValues, "("+strings.Join(andValues, " AND ")+")")
+	}
+
+	return "(" + strings.Join(orValues, " OR ") + ")", args
+}
+
+func (repo *PersonPostgresqlRepository) GetAllByCursor(ctx context.Context, opts domain.PersonsCursorQuery) ([]domain.Person, error) {
+	var persons []domain.Person
+
+	conValues, args := filterConditions(opts.PersonFiltersQuery)
+	columns := orderColumns(opts.Sort)
+
+	if opts.After != nil {
+		condition, values := keysetCondition(columns, opts.After, false, len(args)+1)
+		conValues = append(conValues, condition)
+		args = append(args, values...)
+	}
+
+	if opts.Before != nil {
+		condition, values := keysetCondition(columns, opts.Before, true, len(args)+1)
+		conValues = append(conValues, condition)
+		args = append(args, values...)
+	}
+
+	// one extra row tells whether there is another page
+	args = append(args, opts.Limit+1)
+	orderBy := joinOrder(columns, opts.Backward)
 
-	query := fmt.Sprintf("SELECT * FROM %s LIMIT $%d OFFSET $%d", personsTable, argID, argID+1)
+	query := fmt.Sprintf("SELECT * FROM %s ORDER BY %s LIMIT $%d", personsTable, orderBy, len(args))
 	if len(conValues) > 0 {
-		query = fmt.Sprintf("SELECT * FROM %s WHERE %s LIMIT $%d OFFSET $%d", personsTable, conQuery, argID, argID+1)
+		query = fmt.Sprintf("SELECT * FROM %s WHERE %s ORDER BY %s LIMIT $%d", personsTable, strings.Join(conValues, " AND "), orderBy, len(args))
 	}
 
-	if err := repo.db.Select(&persons, query, args...); err != nil {
-		return []domain.Person{}, postgres.ParsePostgresError(err)
+	if err := repo.db.SelectContext(ctx, &persons, query, args...); err != nil {
+		return []domain.Person{}, parsePostgresError(err)
 	}
 
 	return persons, nil
 }
 
-func (repo *PersonPostgresqlRepository) Add(person domain.Person) (int, error) {
-	var personID int
+func (repo *PersonPostgresqlRepository) GetByID(ctx context.Context, personID int) (domain.Person, error) {
+	var person domain.Person
+
+	query := fmt.Sprintf("SELECT * FROM %s WHERE id = $1", personsTable)
+
+	if err := repo.db.GetContext(ctx, &person, query, personID); err != nil {
+		err = parsePostgresError(err)
+		if errors.Is(err, postgres.ErrNotFound) {
+			return domain.Person{}, domain.ErrPersonNotFound
+		}
+		return domain.Person{}, err
+	}
+
+	return person, nil
+}
+
+// addQuery inserts a person and queues pending persons for enrichment in the
+// same statement, so no pending person is left without a job.
+var addQuery = fmt.Sprintf(`WITH person AS (
+	INSERT INTO %s (name, surname, patronymic, age, gender, nationality, enrichment, enrichment_status, provenance)
+	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) RETURNING id, enrichment_status
+), job AS (
+	INSERT INTO %s (person_id) SELECT id FROM person WHERE enrichment_status = '%s'
+)
+SELECT id FROM person`, personsTable, enrichmentJobsTable, domain.EnrichmentPending)
 
-	query := fmt.Sprintf("INSERT INTO %s (name, surname, patronymic, age, gender, nationality) VALUES ($1,$2,$3,$4,$5,$6) RETURNING id", personsTable)
+func (repo *PersonPostgresqlRepository) Add(ctx context.Context, person domain.Person) (int, error) {
+	var personID int
 
-	row := repo.db.QueryRow(query, person.Name, person.Surname, person.Patronymic, person.Age, person.Gender, person.Nationality)
+	row := repo.db.QueryRowContext(ctx, addQuery, person.Name, person.Surname, person.Patronymic, person.Age, person.Gender,
+		person.Nationality, person.Enrichment, person.EnrichmentStatus, person.Provenance)
 	err := row.Scan(&personID)
 	if err != nil {
-		return 0, postgres.ParsePostgresError(err)
+		return 0, parsePostgresError(err)
 	}
 	return personID, nil
 }
 
-func (repo *PersonPostgresqlRepository) Delete(personID int) (bool, error) {
+// AddBatch adds all persons in one transaction, either all of them are added or none.
+func (repo *PersonPostgresqlRepository) AddBatch(ctx context.Context, persons []domain.Person) ([]int, error) {
+	tx, err := repo.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	personIDs := make([]int, len(persons))
+	for i, person := range persons {
+		row := tx.QueryRowContext(ctx, addQuery, person.Name, person.Surname, person.Patronymic, person.Age, person.Gender,
+			person.Nationality, person.Enrichment, person.EnrichmentStatus, person.Provenance)
+		if err = row.Scan(&personIDs[i]); err != nil {
+			return nil, parsePostgresError(err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return personIDs, nil
+}
+
+// ClaimEnrichment takes up to limit jobs that are due, the longest waiting
+// first, and leases them for the lease duration. A job whose worker dies is
+// claimed again once its lease runs out. Jobs claimed by others are skipped.
+func (repo *PersonPostgresqlRepository) ClaimEnrichment(ctx context.Context, limit int, lease time.Duration) ([]domain.EnrichmentJob, error) {
+	var jobs []domain.EnrichmentJob
+
+	query := fmt.Sprintf(`WITH claimed AS (
+	UPDATE %[1]s SET attempts = attempts + 1, run_at = now() + make_interval(secs => $2), leased_until = now() + make_interval(secs => $2)
+	WHERE person_id IN (SELECT person_id FROM %[1]s WHERE run_at <= now() ORDER BY run_at LIMIT $1 FOR UPDATE SKIP LOCKED)
+	RETURNING person_id, run_id, attempts, force
+)
+SELECT p.*, c.run_id, c.attempts, c.force FROM %[2]s p JOIN claimed c ON c.person_id = p.id ORDER BY p.id`, enrichmentJobsTable, personsTable)
+
+	if err := repo.db.SelectContext(ctx, &jobs, query, limit, lease.Seconds()); err != nil {
+		return nil, parsePostgresError(err)
+	}
+
+	return jobs, nil
+}
+
+// claimedJob matches the job row of the claim only, a job queued again or
+// claimed by another worker meanwhile is left alone.
+func claimedJob(personArg, runArg, attemptsArg int) string {
+	return fmt.Sprintf("person_id = $%d AND run_id IS NOT DISTINCT FROM $%d AND attempts = $%d", personArg, runArg, attemptsArg)
+}
+
+// CompleteEnrichment saves the enriched attributes of the person of the job
+// and drops the job. Unless forced, fields an operator set are kept, even if
+// they were set while the person was being enriched. Nothing is saved if the
+// job isn't the claimed one anymore.
+func (repo *PersonPostgresqlRepository) CompleteEnrichment(ctx context.Context, job domain.EnrichmentJob) error {
+	query := fmt.Sprintf(`WITH job AS (DELETE FROM %s WHERE %s RETURNING person_id)
+UPDATE %s SET %s, %s, %s, enrichment=$4,
+	provenance = coalesce(provenance, '{}'::jsonb) || coalesce((SELECT jsonb_object_agg(key, value) FROM jsonb_each($5::jsonb)
+		WHERE $7 OR provenance->key->>'source' IS DISTINCT FROM '%s'), '{}'::jsonb),
+	enrichment_status='%s'
+WHERE id = (SELECT person_id FROM job)`, enrichmentJobsTable, claimedJob(6, 8, 9), personsTable,
+		keepManual("age", 1, 7), keepManual("gender", 2, 7), keepManual("nationality", 3, 7),
+		domain.ProvenanceManual, domain.EnrichmentComplete)
+
+	person := job.Person
+	_, err := repo.db.ExecContext(ctx, query, person.Age, person.Gender, person.Nationality, person.Enrichment,
+		person.Provenance, person.ID, job.Force, job.RunID, job.Attempts)
+	return parsePostgresError(err)
+}
+
+// keepManual sets the column to the value unless an operator set it and the
+// write isn't forced.
+func keepManual(column string, valueArg, forceArg int) string {
+	return fmt.Sprintf("%[1]s = CASE WHEN $%[3]d OR provenance->'%[1]s'->>'source' IS DISTINCT FROM '%[4]s' THEN $%[2]d ELSE %[1]s END",
+		column, valueArg, forceArg, domain.ProvenanceManual)
+}
+
+// RetryEnrichment puts the claimed job off until runAt.
+func (repo *PersonPostgresqlRepository) RetryEnrichment(ctx context.Context, job domain.EnrichmentJob, runAt time.Time, reason string) error {
+	query := fmt.Sprintf("UPDATE %s SET run_at=$1, leased_until=NULL, last_error=$2 WHERE %s",
+		enrichmentJobsTable, claimedJob(3, 4, 5))
+
+	_, err := repo.db.ExecContext(ctx, query, runAt, reason, job.ID, job.RunID, job.Attempts)
+	return parsePostgresError(err)
+}
+
+// FailEnrichment marks the person of the claimed job as failed. The job is
+// kept with the last error but is never due again.
+func (repo *PersonPostgresqlRepository) FailEnrichment(ctx context.Context, job domain.EnrichmentJob, reason string) error {
+	query := fmt.Sprintf(`WITH job AS (UPDATE %s SET run_at=NULL, leased_until=NULL, last_error=$1 WHERE %s RETURNING person_id)
+UPDATE %s SET enrichment_status='%s' WHERE id = (SELECT person_id FROM job)`,
+		enrichmentJobsTable, claimedJob(2, 3, 4), personsTable, domain.EnrichmentFailed)
+
+	_, err := repo.db.ExecContext(ctx, query, reason, job.ID, job.RunID, job.Attempts)
+	return parsePostgresError(err)
+}
+
+// CreateEnrichmentRun queues the persons matching filters for enrichment, the
+// person with the lowest id first and then one every 1/rate seconds. Queued
+// persons keep their earlier turn and failed ones are tried again. Persons
+// being enriched keep their lease, the run takes them over afterwards.
+func (repo *PersonPostgresqlRepository) CreateEnrichmentRun(ctx context.Context, filters domain.PersonFiltersQuery, rate float64, force bool) (int, error) {
+	tx, err := repo.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	var runID int
+	query := fmt.Sprintf("INSERT INTO %s (filters, rate, force) VALUES ($1, $2, $3) RETURNING id", enrichmentRunsTable)
+	if err = tx.QueryRowContext(ctx, query, filters.String(), rate, force).Scan(&runID); err != nil {
+		return 0, parsePostgresError(err)
+	}
+
+	conValues, args := filterConditions(filters)
+	where := ""
+	if len(conValues) > 0 {
+		where = "WHERE " + strings.Join(conValues, " AND ")
+	}
+	args = append(args, runID, rate, force)
+	query = fmt.Sprintf(`INSERT INTO %[1]s (person_id, run_id, run_at, force)
+SELECT id, $%[3]d, now() + make_interval(secs => (row_number() OVER (ORDER BY id) - 1) / $%[4]d::float8), $%[5]d
+FROM %[2]s %[6]s
+ON CONFLICT (person_id) DO UPDATE SET run_id = excluded.run_id, attempts = 0, last_error = NULL,
+	run_at = CASE WHEN %[1]s.leased_until > now() THEN %[1]s.run_at ELSE LEAST(%[1]s.run_at, excluded.run_at) END,
+	force = excluded.force`,
+		enrichmentJobsTable, personsTable, len(args)-2, len(args)-1, len(args), where)
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+		return 0, parsePostgresError(err)
+	}
+
+	query = fmt.Sprintf("UPDATE %s SET enrichment_status='%s' WHERE id IN (SELECT person_id FROM %s WHERE run_id = $1)",
+		personsTable, domain.EnrichmentPending, enrichmentJobsTable)
+	res, err := tx.ExecContext(ctx, query, runID)
+	if err != nil {
+		return 0, parsePostgresError(err)
+	}
+	total, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	query = fmt.Sprintf("UPDATE %s SET total=$1 WHERE id = $2", enrichmentRunsTable)
+	if _, err = tx.ExecContext(ctx, query, total, runID); err != nil {
+		return 0, parsePostgresError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return runID, nil
+}
+
+// GetEnrichmentRun returns the run with its progress.
+func (repo *PersonPostgresqlRepository) GetEnrichmentRun(ctx context.Context, runID int) (domain.EnrichmentRun, error) {
+	var run domain.EnrichmentRun
+
+	query := fmt.Sprintf(`SELECT r.id, r.filters, r.rate, r.force, r.total, r.created_at,
+	count(j.person_id) FILTER (WHERE j.run_at IS NOT NULL) AS pending,
+	count(j.person_id) FILTER (WHERE j.run_at IS NULL) AS failed
+FROM %s r LEFT JOIN %s j ON j.run_id = r.id WHERE r.id = $1 GROUP BY r.id`, enrichmentRunsTable, enrichmentJobsTable)
+
+	if err := repo.db.GetContext(ctx, &run, query, runID); err != nil {
+		err = parsePostgresError(err)
+		if errors.Is(err, postgres.ErrNotFound) {
+			return domain.EnrichmentRun{}, domain.ErrEnrichmentRunNotFound
+		}
+		return domain.EnrichmentRun{}, err
+	}
+
+	// persons deleted meanwhile are counted as done
+	run.Done = run.Total - run.Pending - run.Failed
+	if run.Done < 0 {
+		run.Done = 0
+	}
+	return run, nil
+}
+
+// ScheduleEnrichmentRun spreads the queued persons of the run out from now on
+// at the rate of the run, so a resumed run doesn't enrich all overdue persons
+// at once. Persons being enriched keep their lease.
+func (repo *PersonPostgresqlRepository) ScheduleEnrichmentRun(ctx context.Context, runID int) error {
+	query := fmt.Sprintf(`UPDATE %[1]s j SET run_at = now() + make_interval(secs => (q.n - 1) / r.rate)
+FROM (SELECT person_id, row_number() OVER (ORDER BY person_id) AS n FROM %[1]s
+	WHERE run_id = $1 AND run_at IS NOT NULL AND (leased_until IS NULL OR leased_until <= now())) q, %[2]s r
+WHERE j.person_id = q.person_id AND r.id = $1`, enrichmentJobsTable, enrichmentRunsTable)
+
+	_, err := repo.db.ExecContext(ctx, query, runID)
+	return parsePostgresError(err)
+}
+
+func (repo *PersonPostgresqlRepository) Delete(ctx context.Context, personID int) (bool, error) {
 	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", personsTable)
 
-	res, err := repo.db.Exec(query, personID)
+	res, err := repo.db.ExecContext(ctx, query, personID)
 	if err != nil {
-		return false, postgres.ParsePostgresError(err)
+		return false, parsePostgresError(err)
 	}
 	deleted, err := res.RowsAffected()
 	return deleted > 0, err
 }
 
-func (repo *PersonPostgresqlRepository) Update(personID int, input domain.UpdatePersonInput) (bool, error) {
+func (repo *PersonPostgresqlRepository) Update(ctx context.Context, personID int, input domain.UpdatePersonInput) (bool, error) {
 	setValues := make([]string, 0)
 	args := make([]interface{}, 0)
 	argID := 1
@@ -127,9 +522,9 @@ func (repo *PersonPostgresqlRepository) Update(personID int, input domain.Update
 		argID++
 	}
 
-	if input.Patronymic != nil {
+	if input.Patronymic.Set {
 		setValues = append(setValues, fmt.Sprintf("patronymic=$%d", argID))
-		args = append(args, *input.Patronymic)
+		args = append(args, input.Patronymic.Ptr())
 		argID++
 	}
 
@@ -139,15 +534,49 @@ func (repo *PersonPostgresqlRepository) Update(personID int, input domain.Update
 		argID++
 	}
 
+	if input.Provenance != nil {
+		setValues = append(setValues, fmt.Sprintf("provenance=coalesce(provenance, '{}'::jsonb) || $%d", argID))
+		args = append(args, input.Provenance)
+		argID++
+	}
+
 	setQuery := strings.Join(setValues, ", ")
 
 	query := fmt.Sprintf(`UPDATE %s SET %s WHERE id = $%d`, personsTable, setQuery, argID)
 	args = append(args, personID)
 
-	res, err := repo.db.Exec(query, args...)
+	res, err := repo.db.ExecContext(ctx, query, args...)
 	if err != nil {
-		return false, postgres.ParsePostgresError(err)
+		return false, parsePostgresError(err)
 	}
 	updated, err := res.RowsAffected()
 	return updated > 0, err
 }
+
+func (repo *PersonPostgresqlRepository) Replace(ctx context.Context, personID int, person domain.Person) (bool, error) {
+	// a replacement has all attributes, there is nothing left to enrich and
+	// the confidence of the replaced ones no longer applies
+	query := fmt.Sprintf(`WITH job AS (DELETE FROM %s WHERE person_id = $7)
+UPDATE %s SET name=$1, surname=$2, patronymic=$3, age=$4, gender=$5, nationality=$6, provenance=$8,
+	enrichment=NULL, enrichment_status='%s' WHERE id = $7`, enrichmentJobsTable, personsTable, domain.EnrichmentComplete)
+
+	res, err := repo.db.ExecContext(ctx, query, person.Name, person.Surname, person.Patronymic, person.Age, person.Gender,
+		person.Nationality, personID, person.Provenance)
+	if err != nil {
+		return false, parsePostgresError(err)
+	}
+	replaced, err := res.RowsAffected()
+	return replaced > 0, err
+}
+
+// parsePostgresError classifies the database errors clients can act upon.
+func parsePostgresError(err error) error {
+	err = postgres.ParsePostgresError(err)
+	switch {
+	case errors.Is(err, postgres.ErrAlreadyExists):
+		return domain.NewError(domain.CodeConflict, err)
+	case errors.Is(err, postgres.ErrInvalidLimit), errors.Is(err, postgres.ErrInvalidOffset):
+		return domain.NewError(domain.CodeValidation, err)
+	}
+	return err
+}
@@ -1,12 +1,17 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"fio/internal/domain"
 	"fmt"
+	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -21,26 +26,28 @@ func TestPersonPostgres_Add(t *testing.T) {
 	r := NewPersonPostgresqlRepository(sqlxDb)
 
 	tests := []struct {
-		name    string
-		mock    func()
-		input   domain.Person
-		want    int
-		wantErr bool
+		name     string
+		mock     func()
+		input    domain.Person
+		want     int
+		wantErr  bool
+		wantCode domain.ErrorCode
 	}{
 		{
 			name: "OK",
 			mock: func() {
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
 				mock.ExpectQuery(fmt.Sprintf("INSERT INTO %s", personsTable)).
-					WithArgs("TEST", "TEST", "TEST", 54, "TEST", "TEST").WillReturnRows(rows)
+					WithArgs("TEST", "TEST", "TEST", 54, "TEST", "TEST", nil, "pending", nil).WillReturnRows(rows)
 			},
 			input: domain.Person{
-				Name:        "TEST",
-				Surname:     "TEST",
-				Patronymic:  stringPointer("TEST"),
-				Age:         54,
-				Gender:      "TEST",
-				Nationality: "TEST",
+				Name:             "TEST",
+				Surname:          "TEST",
+				Patronymic:       stringPointer("TEST"),
+				Age:              54,
+				Gender:           "TEST",
+				Nationality:      "TEST",
+				EnrichmentStatus: domain.EnrichmentPending,
 			},
 			want: 1,
 		},
@@ -49,7 +56,7 @@ func TestPersonPostgres_Add(t *testing.T) {
 			mock: func() {
 				rows := sqlmock.NewRows([]string{"id"})
 				mock.ExpectQuery(fmt.Sprintf("INSERT INTO %s", personsTable)).
-					WithArgs("", "TEST", "TEST", 54, "TEST", "TEST").WillReturnRows(rows)
+					WithArgs("", "TEST", "TEST", 54, "TEST", "TEST", nil, "", nil).WillReturnRows(rows)
 			},
 			input: domain.Person{
 				Name:        "",
@@ -61,15 +68,34 @@ func TestPersonPostgres_Add(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Already Exists",
+			mock: func() {
+				mock.ExpectQuery(fmt.Sprintf("INSERT INTO %s", personsTable)).
+					WithArgs("TEST", "TEST", nil, 54, "TEST", "TEST", nil, "", nil).WillReturnError(&pq.Error{Code: "23505"})
+			},
+			input: domain.Person{
+				Name:        "TEST",
+				Surname:     "TEST",
+				Age:         54,
+				Gender:      "TEST",
+				Nationality: "TEST",
+			},
+			wantErr:  true,
+			wantCode: domain.CodeConflict,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			got, err := r.Add(tt.input)
+			got, err := r.Add(context.Background(), tt.input)
 			if tt.wantErr {
 				assert.Error(t, err)
+				if tt.wantCode != "" {
+					assert.Equal(t, tt.wantCode, domain.CodeOf(err))
+				}
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.want, got)
@@ -79,7 +105,7 @@ func TestPersonPostgres_Add(t *testing.T) {
 	}
 }
 
-func TestPersonPostgres_Delete(t *testing.T) {
+func TestPersonPostgres_AddBatch(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("can't create mock: %s", err)
@@ -90,29 +116,61 @@ func TestPersonPostgres_Delete(t *testing.T) {
 	r := NewPersonPostgresqlRepository(sqlxDb)
 
 	tests := []struct {
-		name    string
-		mock    func()
-		want    bool
-		input   int
-		wantErr bool
+		name     string
+		mock     func()
+		input    []domain.Person
+		want     []int
+		wantErr  bool
+		wantCode domain.ErrorCode
 	}{
 		{
-			name: "Deleted",
+			name: "OK",
 			mock: func() {
-				mock.ExpectExec(fmt.Sprintf("DELETE FROM %s WHERE (.+)", personsTable)).
-					WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectBegin()
+				mock.ExpectQuery(fmt.Sprintf("INSERT INTO %s", personsTable)).
+					WithArgs("TEST", "TEST", "TEST", 54, "TEST", "TEST", nil, "", nil).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectQuery(fmt.Sprintf("INSERT INTO %s", personsTable)).
+					WithArgs("TEST2", "TEST2", nil, 32, "TEST2", "TEST2", nil, "", nil).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+				mock.ExpectCommit()
 			},
-			want:  true,
-			input: 1,
+			input: []domain.Person{
+				{
+					Name:        "TEST",
+					Surname:     "TEST",
+					Patronymic:  stringPointer("TEST"),
+					Age:         54,
+					Gender:      "TEST",
+					Nationality: "TEST",
+				},
+				{
+					Name:        "TEST2",
+					Surname:     "TEST2",
+					Age:         32,
+					Gender:      "TEST2",
+					Nationality: "TEST2",
+				},
+			},
+			want: []int{1, 2},
 		},
 		{
-			name: "Not Deleted",
+			name: "Already Exists",
 			mock: func() {
-				mock.ExpectExec(fmt.Sprintf("DELETE FROM %s WHERE (.+)", personsTable)).
-					WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectBegin()
+				mock.ExpectQuery(fmt.Sprintf("INSERT INTO %s", personsTable)).
+					WithArgs("TEST", "TEST", nil, 54, "TEST", "TEST", nil, "", nil).WillReturnError(&pq.Error{Code: "23505"})
+				mock.ExpectRollback()
 			},
-			input: 1,
-			want:  false,
+			input: []domain.Person{
+				{
+					Name:        "TEST",
+					Surname:     "TEST",
+					Age:         54,
+					Gender:      "TEST",
+					Nationality: "TEST",
+				},
+			},
+			wantErr:  true,
+			wantCode: domain.CodeConflict,
 		},
 	}
 
@@ -120,9 +178,12 @@ func TestPersonPostgres_Delete(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			got, err := r.Delete(tt.input)
+			got, err := r.AddBatch(context.Background(), tt.input)
 			if tt.wantErr {
 				assert.Error(t, err)
+				if tt.wantCode != "" {
+					assert.Equal(t, tt.wantCode, domain.CodeOf(err))
+				}
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.want, got)
@@ -132,155 +193,228 @@ func TestPersonPostgres_Delete(t *testing.T) {
 	}
 }
 
-func TestPersonPostgres_Update(t *testing.T) {
+func TestPersonPostgres_GetAll(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
-		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		t.Fatalf("can't create mock: %s", err)
 	}
 	defer db.Close()
 
 	sqlxDb := sqlx.NewDb(db, "sqlmock")
 	r := NewPersonPostgresqlRepository(sqlxDb)
 
-	type args struct {
-		personID int
-		input    domain.UpdatePersonInput
-	}
+	columns := []string{"id", "name", "surname", "patronymic", "age", "gender", "nationality"}
+
 	tests := []struct {
 		name    string
 		mock    func()
-		input   args
+		input   domain.PersonsQuery
+		want    []domain.Person
 		wantErr bool
-		want    bool
 	}{
 		{
-			name: "OK_AllFields",
+			name: "OK_DefaultOrder",
 			mock: func() {
-				mock.ExpectExec(fmt.Sprintf("UPDATE %s SET (.+) WHERE (.+)", personsTable)).
-					WithArgs(25, "new gender", "new name", "new nationality", "new patronymic", "new surname", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				rows := sqlmock.NewRows(columns).AddRow(1, "TEST", "TEST", nil, 54, "TEST", "TEST")
+				mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf("SELECT * FROM %s ORDER BY id ASC LIMIT $1 OFFSET $2", personsTable))).
+					WithArgs(25, 0).WillReturnRows(rows)
 			},
-			input: args{
-				personID: 1,
-				input: domain.UpdatePersonInput{
-					Age:         intPointer(25),
-					Gender:      stringPointer("new gender"),
-					Name:        stringPointer("new name"),
-					Nationality: stringPointer("new nationality"),
-					Patronymic:  stringPointer("new patronymic"),
-					Surname:     stringPointer("new surname"),
-				},
+			input: domain.PersonsQuery{PaginationQuery: domain.PaginationQuery{Limit: 25}},
+			want:  []domain.Person{{ID: 1, Name: "TEST", Surname: "TEST", Age: 54, Gender: "TEST", Nationality: "TEST"}},
+		},
+		{
+			name: "OK_SortWithFilter",
+			mock: func() {
+				rows := sqlmock.NewRows(columns).AddRow(1, "TEST", "TEST", nil, 54, "TEST", "TEST")
+				mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf("SELECT * FROM %s WHERE gender=$1 ORDER BY age DESC, surname ASC, id ASC LIMIT $2 OFFSET $3", personsTable))).
+					WithArgs("TEST", 25, 0).WillReturnRows(rows)
 			},
-			want: true,
+			input: domain.PersonsQuery{
+				PaginationQuery:    domain.PaginationQuery{Limit: 25},
+				PersonFiltersQuery: domain.PersonFiltersQuery{Gender: stringPointer("TEST")},
+				Sort:               []domain.SortField{{Field: "age", Desc: true}, {Field: "surname"}},
+			},
+			want: []domain.Person{{ID: 1, Name: "TEST", Surname: "TEST", Age: 54, Gender: "TEST", Nationality: "TEST"}},
 		},
 		{
-			name: "OK_NotUpdated",
+			name: "OK_RangeAndSetFilters",
 			mock: func() {
-				mock.ExpectExec(fmt.Sprintf("UPDATE %s SET (.+) WHERE (.+)", personsTable)).
-					WithArgs(25, "new gender", "new name", "new nationality", "new patronymic", "new surname", 1).WillReturnResult(sqlmock.NewResult(0, 0))
+				rows := sqlmock.NewRows(columns)
+				mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf("SELECT * FROM %s WHERE age>=$1 AND age<=$2 AND gender=ANY($3) "+
+					"AND nationality=ANY($4) AND patronymic IS NULL ORDER BY id ASC LIMIT $5 OFFSET $6", personsTable))).
+					WithArgs(18, 65, pq.Array([]string{"male"}), pq.Array([]string{"RU", "UA"}), 25, 0).WillReturnRows(rows)
 			},
-			input: args{
-				personID: 1,
-				input: domain.UpdatePersonInput{
-					Age:         intPointer(25),
-					Gender:      stringPointer("new gender"),
-					Name:        stringPointer("new name"),
-					Nationality: stringPointer("new nationality"),
-					Patronymic:  stringPointer("new patronymic"),
-					Surname:     stringPointer("new surname"),
+			input: domain.PersonsQuery{
+				PaginationQuery: domain.PaginationQuery{Limit: 25},
+				PersonFiltersQuery: domain.PersonFiltersQuery{
+					AgeMin:           intPointer(18),
+					AgeMax:           intPointer(65),
+					GenderIn:         []string{"male"},
+					NationalityIn:    []string{"RU", "UA"},
+					PatronymicIsNull: boolPointer(true),
 				},
 			},
-			want: false,
 		},
 		{
-			name: "OK_WithoutSurname",
+			name: "OK_PrefixMatch",
 			mock: func() {
-				mock.ExpectExec(fmt.Sprintf("UPDATE %s SET (.+) WHERE (.+)", personsTable)).
-					WithArgs(25, "new gender", "new name", "new nationality", "new patronymic", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				rows := sqlmock.NewRows(columns)
+				mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf("SELECT * FROM %s WHERE name ILIKE $1 AND surname ILIKE $2 ORDER BY id ASC LIMIT $3 OFFSET $4", personsTable))).
+					WithArgs("dmi%", `100\%\_%`, 25, 0).WillReturnRows(rows)
 			},
-			input: args{
-				personID: 1,
-				input: domain.UpdatePersonInput{
-					Age:         intPointer(25),
-					Gender:      stringPointer("new gender"),
-					Name:        stringPointer("new name"),
-					Nationality: stringPointer("new nationality"),
-					Patronymic:  stringPointer("new patronymic"),
+			input: domain.PersonsQuery{
+				PaginationQuery: domain.PaginationQuery{Limit: 25},
+				PersonFiltersQuery: domain.PersonFiltersQuery{
+					Name:    stringPointer("dmi"),
+					Surname: stringPointer("100%_"),
+					Match:   domain.MatchPrefix,
 				},
 			},
-			want: true,
 		},
 		{
-			name: "OK_WithoutSurnameAndPatronymic",
+			name: "OK_ContainsMatch",
 			mock: func() {
-				mock.ExpectExec(fmt.Sprintf("UPDATE %s SET (.+) WHERE (.+)", personsTable)).
-					WithArgs(25, "new gender", "new name", "new nationality", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				rows := sqlmock.NewRows(columns)
+				mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf("SELECT * FROM %s WHERE patronymic ILIKE $1 ORDER BY id ASC LIMIT $2 OFFSET $3", personsTable))).
+					WithArgs("%vich%", 25, 0).WillReturnRows(rows)
 			},
-			input: args{
-				personID: 1,
-				input: domain.UpdatePersonInput{
-					Age:         intPointer(25),
-					Gender:      stringPointer("new gender"),
-					Name:        stringPointer("new name"),
-					Nationality: stringPointer("new nationality"),
+			input: domain.PersonsQuery{
+				PaginationQuery: domain.PaginationQuery{Limit: 25},
+				PersonFiltersQuery: domain.PersonFiltersQuery{
+					Patronymic: stringPointer("vich"),
+					Match:      domain.MatchContains,
 				},
 			},
-			want: true,
 		},
 		{
-			name: "OK_WithoutSurnameAndPatronymicAndNationality",
+			name: "OK_SearchRankedByRelevance",
 			mock: func() {
-				mock.ExpectExec(fmt.Sprintf("UPDATE %s SET (.+) WHERE (.+)", personsTable)).
-					WithArgs(25, "new gender", "new name", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				rows := sqlmock.NewRows(columns)
+				mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf("SELECT * FROM %s WHERE $1 <%% %s ORDER BY word_similarity($2, %s) DESC, id ASC LIMIT $3 OFFSET $4",
+					personsTable, fullNameExpr, fullNameExpr))).
+					WithArgs("ushakov", "ushakov", 25, 0).WillReturnRows(rows)
 			},
-			input: args{
-				personID: 1,
-				input: domain.UpdatePersonInput{
-					Age:    intPointer(25),
-					Gender: stringPointer("new gender"),
-					Name:   stringPointer("new name"),
-				},
+			input: domain.PersonsQuery{
+				PaginationQuery:    domain.PaginationQuery{Limit: 25},
+				PersonFiltersQuery: domain.PersonFiltersQuery{Q: stringPointer(" ushakov ")},
 			},
-			want: true,
 		},
 		{
-			name: "OK_WithoutSurnameAndPatronymicAndNationalityAndName",
+			name: "OK_SearchWithSort",
 			mock: func() {
-				mock.ExpectExec(fmt.Sprintf("UPDATE %s SET (.+) WHERE (.+)", personsTable)).
-					WithArgs(25, "new gender", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				rows := sqlmock.NewRows(columns)
+				mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf("SELECT * FROM %s WHERE $1 <%% %s ORDER BY age ASC, id ASC LIMIT $2 OFFSET $3",
+					personsTable, fullNameExpr))).
+					WithArgs("ushakov", 25, 0).WillReturnRows(rows)
 			},
-			input: args{
-				personID: 1,
-				input: domain.UpdatePersonInput{
-					Age:    intPointer(25),
-					Gender: stringPointer("new gender"),
-				},
+			input: domain.PersonsQuery{
+				PaginationQuery:    domain.PaginationQuery{Limit: 25},
+				PersonFiltersQuery: domain.PersonFiltersQuery{Q: stringPointer("ushakov")},
+				Sort:               []domain.SortField{{Field: "age"}},
 			},
-			want: true,
 		},
 		{
-			name: "OK_WithoutSurnameAndPatronymicAndNationalityAndNameAndGender",
+			name: "OK_SortByID",
 			mock: func() {
-				mock.ExpectExec(fmt.Sprintf("UPDATE %s SET (.+) WHERE (.+)", personsTable)).
-					WithArgs(25, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				rows := sqlmock.NewRows(columns)
+				mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf("SELECT * FROM %s ORDER BY id DESC LIMIT $1 OFFSET $2", personsTable))).
+					WithArgs(25, 0).WillReturnRows(rows)
 			},
-			input: args{
-				personID: 1,
-				input: domain.UpdatePersonInput{
-					Age: intPointer(25),
-				},
+			input: domain.PersonsQuery{
+				PaginationQuery: domain.PaginationQuery{Limit: 25},
+				Sort:            []domain.SortField{{Field: "id", Desc: true}},
 			},
-			want: true,
 		},
 		{
-			name: "OK_NoInputFields",
+			name: "DB Error",
 			mock: func() {
-				mock.ExpectExec(fmt.Sprintf("UPDATE %s SET WHERE (.+)", personsTable)).
-					WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(fmt.Sprintf("SELECT (.+) FROM %s", personsTable)).
+					WithArgs(25, 0).WillReturnError(errors.New("something went wrong"))
 			},
-			input: args{
-				personID: 1,
+			input:   domain.PersonsQuery{PaginationQuery: domain.PaginationQuery{Limit: 25}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.GetAll(context.Background(), tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPersonPostgres_GetAllByCursor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+	}
+	defer db.Close()
+
+	sqlxDb := sqlx.NewDb(db, "sqlmock")
+	r := NewPersonPostgresqlRepository(sqlxDb)
+
+	columns := []string{"id", "name", "surname", "patronymic", "age", "gender", "nationality"}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		input   domain.PersonsCursorQuery
+		want    []domain.Person
+		wantErr bool
+	}{
+		{
+			name: "OK_FirstPage",
+			mock: func() {
+				rows := sqlmock.NewRows(columns).AddRow(1, "TEST", "TEST", nil, 54, "TEST", "TEST")
+				mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf("SELECT * FROM %s ORDER BY id ASC LIMIT $1", personsTable))).
+					WithArgs(11).WillReturnRows(rows)
 			},
-			want: false,
+			input: domain.PersonsCursorQuery{CursorQuery: domain.CursorQuery{Limit: 10}},
+			want:  []domain.Person{{ID: 1, Name: "TEST", Surname: "TEST", Age: 54, Gender: "TEST", Nationality: "TEST"}},
+		},
+		{
+			name: "OK_AfterWithSort",
+			mock: func() {
+				rows := sqlmock.NewRows(columns)
+				mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf("SELECT * FROM %s WHERE gender=$1 AND "+
+					"((age<$2) OR (age=$2 AND surname>$3) OR (age=$2 AND surname=$3 AND id>$4)) "+
+					"ORDER BY age DESC, surname ASC, id ASC LIMIT $5", personsTable))).
+					WithArgs("male", 54, "TEST", 7, 11).WillReturnRows(rows)
+			},
+			input: domain.PersonsCursorQuery{
+				CursorQuery:        domain.CursorQuery{Limit: 10, After: &domain.Cursor{Values: []interface{}{54, "TEST"}, ID: 7}},
+				PersonFiltersQuery: domain.PersonFiltersQuery{Gender: stringPointer("male")},
+				Sort:               []domain.SortField{{Field: "age", Desc: true}, {Field: "surname"}},
+			},
+		},
+		{
+			name: "OK_Backward",
+			mock: func() {
+				rows := sqlmock.NewRows(columns)
+				mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf("SELECT * FROM %s WHERE ((id<$1)) ORDER BY id DESC LIMIT $2", personsTable))).
+					WithArgs(7, 11).WillReturnRows(rows)
+			},
+			input: domain.PersonsCursorQuery{
+				CursorQuery: domain.CursorQuery{Limit: 10, Before: &domain.Cursor{ID: 7}, Backward: true},
+			},
+		},
+		{
+			name: "DB Error",
+			mock: func() {
+				mock.ExpectQuery(fmt.Sprintf("SELECT (.+) FROM %s", personsTable)).
+					WithArgs(11).WillReturnError(errors.New("something went wrong"))
+			},
+			input:   domain.PersonsCursorQuery{CursorQuery: domain.CursorQuery{Limit: 10}},
+			wantErr: true,
 		},
 	}
 
@@ -288,7 +422,7 @@ func TestPersonPostgres_Update(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			got, err := r.Update(tt.input.personID, tt.input.input)
+			got, err := r.GetAllByCursor(context.Background(), tt.input)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -300,10 +434,763 @@ func TestPersonPostgres_Update(t *testing.T) {
 	}
 }
 
-func stringPointer(s string) *string {
-	return &s
+func TestPersonPostgres_Count(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+	}
+	defer db.Close()
+
+	sqlxDb := sqlx.NewDb(db, "sqlmock")
+	r := NewPersonPostgresqlRepository(sqlxDb)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		input   domain.PersonFiltersQuery
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "OK_NoFilters",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"count"}).AddRow(120)
+				mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf("SELECT count(*) FROM %s", personsTable))).
+					WillReturnRows(rows)
+			},
+			want: 120,
+		},
+		{
+			name: "OK_SharedFilters",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"count"}).AddRow(3)
+				mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf("SELECT count(*) FROM %s WHERE gender=$1 AND age>=$2", personsTable))).
+					WithArgs("male", 18).WillReturnRows(rows)
+			},
+			input: domain.PersonFiltersQuery{Gender: stringPointer("male"), AgeMin: intPointer(18)},
+			want:  3,
+		},
+		{
+			name: "DB Error",
+			mock: func() {
+				mock.ExpectQuery(fmt.Sprintf("SELECT (.+) FROM %s", personsTable)).
+					WillReturnError(errors.New("something went wrong"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.Count(context.Background(), tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
 }
 
-func intPointer(i int) *int {
-	return &i
+func TestPersonPostgres_GetByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+	}
+	defer db.Close()
+
+	sqlxDb := sqlx.NewDb(db, "sqlmock")
+	r := NewPersonPostgresqlRepository(sqlxDb)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		input   int
+		want    domain.Person
+		wantErr error
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "name", "surname", "patronymic", "age", "gender", "nationality"}).
+					AddRow(1, "TEST", "TEST", "TEST", 54, "TEST", "TEST")
+				mock.ExpectQuery(fmt.Sprintf("SELECT (.+) FROM %s WHERE (.+)", personsTable)).
+					WithArgs(1).WillReturnRows(rows)
+			},
+			input: 1,
+			want: domain.Person{
+				ID:          1,
+				Name:        "TEST",
+				Surname:     "TEST",
+				Patronymic:  stringPointer("TEST"),
+				Age:         54,
+				Gender:      "TEST",
+				Nationality: "TEST",
+			},
+		},
+		{
+			name: "Not Found",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "name", "surname", "patronymic", "age", "gender", "nationality"})
+				mock.ExpectQuery(fmt.Sprintf("SELECT (.+) FROM %s WHERE (.+)", personsTable)).
+					WithArgs(1).WillReturnRows(rows)
+			},
+			input:   1,
+			wantErr: domain.ErrPersonNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.GetByID(context.Background(), tt.input)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPersonPostgres_Delete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+	}
+	defer db.Close()
+
+	sqlxDb := sqlx.NewDb(db, "sqlmock")
+	r := NewPersonPostgresqlRepository(sqlxDb)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    bool
+		input   int
+		wantErr bool
+	}{
+		{
+			name: "Deleted",
+			mock: func() {
+				mock.ExpectExec(fmt.Sprintf("DELETE FROM %s WHERE (.+)", personsTable)).
+					WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			want:  true,
+			input: 1,
+		},
+		{
+			name: "Not Deleted",
+			mock: func() {
+				mock.ExpectExec(fmt.Sprintf("DELETE FROM %s WHERE (.+)", personsTable)).
+					WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			input: 1,
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.Delete(context.Background(), tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPersonPostgres_Update(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDb := sqlx.NewDb(db, "sqlmock")
+	r := NewPersonPostgresqlRepository(sqlxDb)
+
+	type args struct {
+		personID int
+		input    domain.UpdatePersonInput
+	}
+	provenance := &domain.Provenance{
+		Age: &domain.FieldProvenance{Source: domain.ProvenanceManual, UpdatedAt: time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)},
+	}
+	tests := []struct {
+		name    string
+		mock    func()
+		input   args
+		wantErr bool
+		want    bool
+	}{
+		{
+			name: "OK_AllFields",
+			mock: func() {
+				mock.ExpectExec(fmt.Sprintf("UPDATE %s SET (.+) WHERE (.+)", personsTable)).
+					WithArgs(25, "new gender", "new name", "new nationality", "new patronymic", "new surname", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			input: args{
+				personID: 1,
+				input: domain.UpdatePersonInput{
+					Age:         intPointer(25),
+					Gender:      stringPointer("new gender"),
+					Name:        stringPointer("new name"),
+					Nationality: stringPointer("new nationality"),
+					Patronymic:  domain.NewNullString(stringPointer("new patronymic")),
+					Surname:     stringPointer("new surname"),
+				},
+			},
+			want: true,
+		},
+		{
+			name: "OK_WithProvenance",
+			mock: func() {
+				mock.ExpectExec(fmt.Sprintf("UPDATE %s SET (.+) provenance=coalesce(.+) WHERE (.+)", personsTable)).
+					WithArgs(25, provenance, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			input: args{
+				personID: 1,
+				input: domain.UpdatePersonInput{
+					Age:        intPointer(25),
+					Provenance: provenance,
+				},
+			},
+			want: true,
+		},
+		{
+			name: "OK_NotUpdated",
+			mock: func() {
+				mock.ExpectExec(fmt.Sprintf("UPDATE %s SET (.+) WHERE (.+)", personsTable)).
+					WithArgs(25, "new gender", "new name", "new nationality", "new patronymic", "new surname", 1).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			input: args{
+				personID: 1,
+				input: domain.UpdatePersonInput{
+					Age:         intPointer(25),
+					Gender:      stringPointer("new gender"),
+					Name:        stringPointer("new name"),
+					Nationality: stringPointer("new nationality"),
+					Patronymic:  domain.NewNullString(stringPointer("new patronymic")),
+					Surname:     stringPointer("new surname"),
+				},
+			},
+			want: false,
+		},
+		{
+			name: "OK_WithoutSurname",
+			mock: func() {
+				mock.ExpectExec(fmt.Sprintf("UPDATE %s SET (.+) WHERE (.+)", personsTable)).
+					WithArgs(25, "new gender", "new name", "new nationality", "new patronymic", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			input: args{
+				personID: 1,
+				input: domain.UpdatePersonInput{
+					Age:         intPointer(25),
+					Gender:      stringPointer("new gender"),
+					Name:        stringPointer("new name"),
+					Nationality: stringPointer("new nationality"),
+					Patronymic:  domain.NewNullString(stringPointer("new patronymic")),
+				},
+			},
+			want: true,
+		},
+		{
+			name: "OK_ClearPatronymic",
+			mock: func() {
+				mock.ExpectExec(fmt.Sprintf("UPDATE %s SET (.+) WHERE (.+)", personsTable)).
+					WithArgs(nil, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			input: args{
+				personID: 1,
+				input: domain.UpdatePersonInput{
+					Patronymic: domain.NewNullString(nil),
+				},
+			},
+			want: true,
+		},
+		{
+			name: "OK_WithoutSurnameAndPatronymic",
+			mock: func() {
+				mock.ExpectExec(fmt.Sprintf("UPDATE %s SET (.+) WHERE (.+)", personsTable)).
+					WithArgs(25, "new gender", "new name", "new nationality", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			input: args{
+				personID: 1,
+				input: domain.UpdatePersonInput{
+					Age:         intPointer(25),
+					Gender:      stringPointer("new gender"),
+					Name:        stringPointer("new name"),
+					Nationality: stringPointer("new nationality"),
+				},
+			},
+			want: true,
+		},
+		{
+			name: "OK_WithoutSurnameAndPatronymicAndNationality",
+			mock: func() {
+				mock.ExpectExec(fmt.Sprintf("UPDATE %s SET (.+) WHERE (.+)", personsTable)).
+					WithArgs(25, "new gender", "new name", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			input: args{
+				personID: 1,
+				input: domain.UpdatePersonInput{
+					Age:    intPointer(25),
+					Gender: stringPointer("new gender"),
+					Name:   stringPointer("new name"),
+				},
+			},
+			want: true,
+		},
+		{
+			name: "OK_WithoutSurnameAndPatronymicAndNationalityAndName",
+			mock: func() {
+				mock.ExpectExec(fmt.Sprintf("UPDATE %s SET (.+) WHERE (.+)", personsTable)).
+					WithArgs(25, "new gender", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			input: args{
+				personID: 1,
+				input: domain.UpdatePersonInput{
+					Age:    intPointer(25),
+					Gender: stringPointer("new gender"),
+				},
+			},
+			want: true,
+		},
+		{
+			name: "OK_WithoutSurnameAndPatronymicAndNationalityAndNameAndGender",
+			mock: func() {
+				mock.ExpectExec(fmt.Sprintf("UPDATE %s SET (.+) WHERE (.+)", personsTable)).
+					WithArgs(25, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			input: args{
+				personID: 1,
+				input: domain.UpdatePersonInput{
+					Age: intPointer(25),
+				},
+			},
+			want: true,
+		},
+		{
+			name: "OK_NoInputFields",
+			mock: func() {
+				mock.ExpectExec(fmt.Sprintf("UPDATE %s SET WHERE (.+)", personsTable)).
+					WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			input: args{
+				personID: 1,
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.Update(context.Background(), tt.input.personID, tt.input.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPersonPostgres_Replace(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+	}
+	defer db.Close()
+
+	sqlxDb := sqlx.NewDb(db, "sqlmock")
+	r := NewPersonPostgresqlRepository(sqlxDb)
+
+	type args struct {
+		personID int
+		person   domain.Person
+	}
+	tests := []struct {
+		name    string
+		mock    func()
+		input   args
+		wantErr bool
+		want    bool
+	}{
+		{
+			name: "OK_WithoutPatronymic",
+			mock: func() {
+				mock.ExpectExec(fmt.Sprintf("UPDATE %s SET (.+) WHERE (.+)", personsTable)).
+					WithArgs("name", "surname", nil, 25, "male", "RU", 1, nil).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			input: args{
+				personID: 1,
+				person: domain.Person{
+					Name:        "name",
+					Surname:     "surname",
+					Age:         25,
+					Gender:      "male",
+					Nationality: "RU",
+				},
+			},
+			want: true,
+		},
+		{
+			name: "OK_EnrichmentCleared",
+			mock: func() {
+				mock.ExpectExec(fmt.Sprintf("UPDATE %s SET (.+) enrichment=NULL, enrichment_status='%s' WHERE (.+)",
+					personsTable, domain.EnrichmentComplete)).
+					WithArgs("name", "surname", nil, 25, "male", "RU", 1, nil).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			input: args{
+				personID: 1,
+				person: domain.Person{
+					Name:        "name",
+					Surname:     "surname",
+					Age:         25,
+					Gender:      "male",
+					Nationality: "RU",
+					Enrichment:  &domain.Enrichment{},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "OK_NotReplaced",
+			mock: func() {
+				mock.ExpectExec(fmt.Sprintf("UPDATE %s SET (.+) WHERE (.+)", personsTable)).
+					WithArgs("name", "surname", "patronymic", 25, "male", "RU", 1, nil).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			input: args{
+				personID: 1,
+				person: domain.Person{
+					Name:        "name",
+					Surname:     "surname",
+					Patronymic:  stringPointer("patronymic"),
+					Age:         25,
+					Gender:      "male",
+					Nationality: "RU",
+				},
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.Replace(context.Background(), tt.input.personID, tt.input.person)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPersonPostgres_ClaimEnrichment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+	}
+	defer db.Close()
+
+	sqlxDb := sqlx.NewDb(db, "sqlmock")
+	r := NewPersonPostgresqlRepository(sqlxDb)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    []domain.EnrichmentJob
+		wantErr bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "name", "surname", "patronymic", "age", "gender", "nationality", "enrichment_status", "run_id", "attempts"}).
+					AddRow(1, "TEST", "TEST", nil, 0, "", "", "pending", 3, 2)
+				mock.ExpectQuery(fmt.Sprintf("UPDATE %s SET (.+) WHERE (.+) FOR UPDATE SKIP LOCKED", enrichmentJobsTable)).
+					WithArgs(10, 60.0).WillReturnRows(rows)
+			},
+			want: []domain.EnrichmentJob{{
+				Person:   domain.Person{ID: 1, Name: "TEST", Surname: "TEST", EnrichmentStatus: domain.EnrichmentPending},
+				RunID:    intPointer(3),
+				Attempts: 2,
+			}},
+		},
+		{
+			name: "DB Error",
+			mock: func() {
+				mock.ExpectQuery(fmt.Sprintf("UPDATE %s SET (.+)", enrichmentJobsTable)).
+					WithArgs(10, 60.0).WillReturnError(errors.New("something went wrong"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.ClaimEnrichment(context.Background(), 10, time.Minute)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPersonPostgres_CompleteEnrichment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+	}
+	defer db.Close()
+
+	sqlxDb := sqlx.NewDb(db, "sqlmock")
+	r := NewPersonPostgresqlRepository(sqlxDb)
+
+	enriched := &domain.FieldProvenance{Source: domain.ProvenanceEnriched, UpdatedAt: time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)}
+	provenance := &domain.Provenance{Age: enriched, Gender: enriched, Nationality: enriched}
+	mock.ExpectExec(fmt.Sprintf("DELETE FROM %s WHERE person_id = \\$6 AND run_id IS NOT DISTINCT FROM \\$8 AND attempts = \\$9 "+
+		"RETURNING person_id\\) UPDATE %s SET (.+) WHERE id = \\(SELECT person_id FROM job\\)", enrichmentJobsTable, personsTable)).
+		WithArgs(54, "male", "RU", nil, provenance, 1, false, intPointer(3), 2).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = r.CompleteEnrichment(context.Background(), domain.EnrichmentJob{
+		Person:   domain.Person{ID: 1, Age: 54, Gender: "male", Nationality: "RU", Provenance: provenance},
+		RunID:    intPointer(3),
+		Attempts: 2,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPersonPostgres_RetryEnrichment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+	}
+	defer db.Close()
+
+	sqlxDb := sqlx.NewDb(db, "sqlmock")
+	r := NewPersonPostgresqlRepository(sqlxDb)
+
+	runAt := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectExec(fmt.Sprintf("UPDATE %s SET (.+) WHERE (.+)", enrichmentJobsTable)).
+		WithArgs(runAt, "upstream unavailable", 1, nil, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = r.RetryEnrichment(context.Background(), domain.EnrichmentJob{Person: domain.Person{ID: 1}, Attempts: 2}, runAt,
+		"upstream unavailable")
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPersonPostgres_FailEnrichment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+	}
+	defer db.Close()
+
+	sqlxDb := sqlx.NewDb(db, "sqlmock")
+	r := NewPersonPostgresqlRepository(sqlxDb)
+
+	mock.ExpectExec(fmt.Sprintf("UPDATE %s SET (.+) UPDATE %s SET (.+) WHERE (.+)", enrichmentJobsTable, personsTable)).
+		WithArgs("upstream unavailable", 1, intPointer(3), 5).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = r.FailEnrichment(context.Background(), domain.EnrichmentJob{Person: domain.Person{ID: 1}, RunID: intPointer(3), Attempts: 5},
+		"upstream unavailable")
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPersonPostgres_CreateEnrichmentRun(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+	}
+	defer db.Close()
+
+	sqlxDb := sqlx.NewDb(db, "sqlmock")
+	r := NewPersonPostgresqlRepository(sqlxDb)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		filters domain.PersonFiltersQuery
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "OK_All",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(fmt.Sprintf("INSERT INTO %s", enrichmentRunsTable)).
+					WithArgs("", 5.0, false).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+				mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf("INSERT INTO %s (person_id, run_id, run_at, force) SELECT id, $1,", enrichmentJobsTable))+
+					fmt.Sprintf("(.+) FROM %s ON CONFLICT", personsTable)).
+					WithArgs(3, 5.0, false).WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(fmt.Sprintf("UPDATE %s SET (.+) WHERE (.+)", personsTable)).
+					WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(fmt.Sprintf("UPDATE %s SET total", enrichmentRunsTable)).
+					WithArgs(2, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			want: 3,
+		},
+		{
+			name: "OK_Filtered",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(fmt.Sprintf("INSERT INTO %s", enrichmentRunsTable)).
+					WithArgs("gender=male", 5.0, false).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+				mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf("INSERT INTO %s (person_id, run_id, run_at, force) SELECT id, $2,", enrichmentJobsTable))+
+					fmt.Sprintf("(.+) FROM %s WHERE gender=\\$1 ON CONFLICT", personsTable)).
+					WithArgs("male", 3, 5.0, false).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(fmt.Sprintf("UPDATE %s SET (.+) WHERE (.+)", personsTable)).
+					WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(fmt.Sprintf("UPDATE %s SET total", enrichmentRunsTable)).
+					WithArgs(1, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			filters: domain.PersonFiltersQuery{Gender: stringPointer("male")},
+			want:    3,
+		},
+		{
+			name: "DB Error",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(fmt.Sprintf("INSERT INTO %s", enrichmentRunsTable)).
+					WithArgs("", 5.0, false).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+				mock.ExpectExec(fmt.Sprintf("INSERT INTO %s", enrichmentJobsTable)).
+					WithArgs(3, 5.0, false).WillReturnError(errors.New("something went wrong"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.CreateEnrichmentRun(context.Background(), tt.filters, 5, false)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPersonPostgres_GetEnrichmentRun(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+	}
+	defer db.Close()
+
+	sqlxDb := sqlx.NewDb(db, "sqlmock")
+	r := NewPersonPostgresqlRepository(sqlxDb)
+
+	createdAt := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "filters", "rate", "force", "total", "created_at", "pending", "failed"}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    domain.EnrichmentRun
+		wantErr error
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				rows := sqlmock.NewRows(columns).AddRow(3, "gender=male", 5.0, true, 10, createdAt, 4, 1)
+				mock.ExpectQuery(fmt.Sprintf("SELECT (.+) FROM %s r LEFT JOIN %s j (.+) WHERE r.id = (.+)", enrichmentRunsTable, enrichmentJobsTable)).
+					WithArgs(3).WillReturnRows(rows)
+			},
+			want: domain.EnrichmentRun{ID: 3, Filters: "gender=male", Rate: 5, Force: true, Total: 10, Done: 5, Pending: 4, Failed: 1, CreatedAt: createdAt},
+		},
+		{
+			name: "Not Found",
+			mock: func() {
+				mock.ExpectQuery(fmt.Sprintf("SELECT (.+) FROM %s", enrichmentRunsTable)).
+					WithArgs(3).WillReturnRows(sqlmock.NewRows(columns))
+			},
+			wantErr: domain.ErrEnrichmentRunNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			got, err := r.GetEnrichmentRun(context.Background(), 3)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPersonPostgres_ScheduleEnrichmentRun(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+	}
+	defer db.Close()
+
+	sqlxDb := sqlx.NewDb(db, "sqlmock")
+	r := NewPersonPostgresqlRepository(sqlxDb)
+
+	mock.ExpectExec(fmt.Sprintf("UPDATE %s j SET run_at (.+) WHERE run_id = (.+)", enrichmentJobsTable)).
+		WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 4))
+
+	err = r.ScheduleEnrichmentRun(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func stringPointer(s string) *string {
+	return &s
+}
+
+func intPointer(i int) *int {
+	return &i
+}
+
+func boolPointer(b bool) *bool {
+	return &b
 }
@@ -1,20 +1,36 @@
 package repository
 
 import (
+	"context"
 	"fio/internal/domain"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 )
 
 const (
-	personsTable = "persons"
+	personsTable        = "persons"
+	enrichmentJobsTable = "enrichment_jobs"
+	enrichmentRunsTable = "enrichment_runs"
 )
 
 type PersonRepo interface {
-	GetAll(opts domain.PersonsQuery) ([]domain.Person, error)
-	Add(person domain.Person) (int, error)
-	Delete(personID int) (bool, error)
-	Update(personID int, input domain.UpdatePersonInput) (bool, error)
+	GetAll(ctx context.Context, opts domain.PersonsQuery) ([]domain.Person, error)
+	GetAllByCursor(ctx context.Context, opts domain.PersonsCursorQuery) ([]domain.Person, error)
+	GetByID(ctx context.Context, personID int) (domain.Person, error)
+	Count(ctx context.Context, filters domain.PersonFiltersQuery) (int, error)
+	Add(ctx context.Context, person domain.Person) (int, error)
+	AddBatch(ctx context.Context, persons []domain.Person) ([]int, error)
+	Delete(ctx context.Context, personID int) (bool, error)
+	Update(ctx context.Context, personID int, input domain.UpdatePersonInput) (bool, error)
+	Replace(ctx context.Context, personID int, person domain.Person) (bool, error)
+	ClaimEnrichment(ctx context.Context, limit int, lease time.Duration) ([]domain.EnrichmentJob, error)
+	CompleteEnrichment(ctx context.Context, job domain.EnrichmentJob) error
+	RetryEnrichment(ctx context.Context, job domain.EnrichmentJob, runAt time.Time, reason string) error
+	FailEnrichment(ctx context.Context, job domain.EnrichmentJob, reason string) error
+	CreateEnrichmentRun(ctx context.Context, filters domain.PersonFiltersQuery, rate float64, force bool) (int, error)
+	GetEnrichmentRun(ctx context.Context, runID int) (domain.EnrichmentRun, error)
+	ScheduleEnrichmentRun(ctx context.Context, runID int) error
 }
 
 type Repository struct {
@@ -2,15 +2,21 @@ package server
 
 import (
 	"context"
+	"expvar"
 	"fio/internal/config"
+	"net"
 	"net/http"
+	"time"
 )
 
+const debugReadHeaderTimeout = 5 * time.Second
+
 type Server struct {
 	httpServer *http.Server
 }
 
-func NewServer(cfg *config.Config, handler http.Handler) *Server {
+// NewServer serves requests with contexts derived from baseCtx.
+func NewServer(cfg *config.Config, handler http.Handler, baseCtx context.Context) *Server {
 	return &Server{
 		httpServer: &http.Server{
 			Addr:           ":" + cfg.HTTP.Port,
@@ -18,6 +24,23 @@ func NewServer(cfg *config.Config, handler http.Handler) *Server {
 			MaxHeaderBytes: cfg.HTTP.MaxHeaderMegaBytes << 18, //nolint:gomnd
 			ReadTimeout:    cfg.HTTP.ReadTimeout,
 			WriteTimeout:   cfg.HTTP.WriteTimeout,
+			BaseContext: func(net.Listener) context.Context {
+				return baseCtx
+			},
+		},
+	}
+}
+
+// NewDebugServer serves the published expvars on addr, apart from the public API.
+func NewDebugServer(addr string) *Server {
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	return &Server{
+		httpServer: &http.Server{
+			Addr:              addr,
+			Handler:           mux,
+			ReadHeaderTimeout: debugReadHeaderTimeout,
 		},
 	}
 }
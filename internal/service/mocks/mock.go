@@ -1,10 +1,11 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: internal/service/service.go
+// Source: service.go
 
 // Package mock_service is a generated GoMock package.
 package mock_service
 
 import (
+	context "context"
 	domain "fio/internal/domain"
 	reflect "reflect"
 
@@ -35,61 +36,219 @@ func (m *MockPerson) EXPECT() *MockPersonMockRecorder {
 }
 
 // Add mocks base method.
-func (m *MockPerson) Add(person domain.Person) (int, error) {
+func (m *MockPerson) Add(ctx context.Context, person domain.Person) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Add", person)
+	ret := m.ctrl.Call(m, "Add", ctx, person)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Add indicates an expected call of Add.
-func (mr *MockPersonMockRecorder) Add(person interface{}) *gomock.Call {
+func (mr *MockPersonMockRecorder) Add(ctx, person interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockPerson)(nil).Add), person)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockPerson)(nil).Add), ctx, person)
+}
+
+// AddBatch mocks base method.
+func (m *MockPerson) AddBatch(ctx context.Context, persons []domain.Person) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddBatch", ctx, persons)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddBatch indicates an expected call of AddBatch.
+func (mr *MockPersonMockRecorder) AddBatch(ctx, persons interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBatch", reflect.TypeOf((*MockPerson)(nil).AddBatch), ctx, persons)
+}
+
+// Count mocks base method.
+func (m *MockPerson) Count(ctx context.Context, filters domain.PersonFiltersQuery) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", ctx, filters)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockPersonMockRecorder) Count(ctx, filters interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockPerson)(nil).Count), ctx, filters)
 }
 
 // Delete mocks base method.
-func (m *MockPerson) Delete(personID int) (bool, error) {
+func (m *MockPerson) Delete(ctx context.Context, personID int) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", personID)
+	ret := m.ctrl.Call(m, "Delete", ctx, personID)
 	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockPersonMockRecorder) Delete(personID interface{}) *gomock.Call {
+func (mr *MockPersonMockRecorder) Delete(ctx, personID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockPerson)(nil).Delete), personID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockPerson)(nil).Delete), ctx, personID)
 }
 
 // GetAll mocks base method.
-func (m *MockPerson) GetAll(opts domain.PersonsQuery) ([]domain.Person, error) {
+func (m *MockPerson) GetAll(ctx context.Context, opts domain.PersonsQuery) ([]domain.Person, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll", opts)
+	ret := m.ctrl.Call(m, "GetAll", ctx, opts)
 	ret0, _ := ret[0].([]domain.Person)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAll indicates an expected call of GetAll.
-func (mr *MockPersonMockRecorder) GetAll(opts interface{}) *gomock.Call {
+func (mr *MockPersonMockRecorder) GetAll(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockPerson)(nil).GetAll), ctx, opts)
+}
+
+// GetAllByCursor mocks base method.
+func (m *MockPerson) GetAllByCursor(ctx context.Context, opts domain.PersonsCursorQuery) (domain.PersonsConnection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllByCursor", ctx, opts)
+	ret0, _ := ret[0].(domain.PersonsConnection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllByCursor indicates an expected call of GetAllByCursor.
+func (mr *MockPersonMockRecorder) GetAllByCursor(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByCursor", reflect.TypeOf((*MockPerson)(nil).GetAllByCursor), ctx, opts)
+}
+
+// GetByID mocks base method.
+func (m *MockPerson) GetByID(ctx context.Context, personID int) (domain.Person, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, personID)
+	ret0, _ := ret[0].(domain.Person)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockPersonMockRecorder) GetByID(ctx, personID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockPerson)(nil).GetAll), opts)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockPerson)(nil).GetByID), ctx, personID)
+}
+
+// Replace mocks base method.
+func (m *MockPerson) Replace(ctx context.Context, personID int, person domain.Person) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Replace", ctx, personID, person)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Replace indicates an expected call of Replace.
+func (mr *MockPersonMockRecorder) Replace(ctx, personID, person interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Replace", reflect.TypeOf((*MockPerson)(nil).Replace), ctx, personID, person)
 }
 
 // Update mocks base method.
-func (m *MockPerson) Update(personID int, UpdateInput domain.UpdatePersonInput) (bool, error) {
+func (m *MockPerson) Update(ctx context.Context, personID int, UpdateInput domain.UpdatePersonInput) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", personID, UpdateInput)
+	ret := m.ctrl.Call(m, "Update", ctx, personID, UpdateInput)
 	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockPersonMockRecorder) Update(personID, UpdateInput interface{}) *gomock.Call {
+func (mr *MockPersonMockRecorder) Update(ctx, personID, UpdateInput interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockPerson)(nil).Update), ctx, personID, UpdateInput)
+}
+
+// MockEnrichment is a mock of Enrichment interface.
+type MockEnrichment struct {
+	ctrl     *gomock.Controller
+	recorder *MockEnrichmentMockRecorder
+}
+
+// MockEnrichmentMockRecorder is the mock recorder for MockEnrichment.
+type MockEnrichmentMockRecorder struct {
+	mock *MockEnrichment
+}
+
+// NewMockEnrichment creates a new mock instance.
+func NewMockEnrichment(ctrl *gomock.Controller) *MockEnrichment {
+	mock := &MockEnrichment{ctrl: ctrl}
+	mock.recorder = &MockEnrichmentMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEnrichment) EXPECT() *MockEnrichmentMockRecorder {
+	return m.recorder
+}
+
+// EnrichPending mocks base method.
+func (m *MockEnrichment) EnrichPending(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrichPending", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrichPending indicates an expected call of EnrichPending.
+func (mr *MockEnrichmentMockRecorder) EnrichPending(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrichPending", reflect.TypeOf((*MockEnrichment)(nil).EnrichPending), ctx)
+}
+
+// GetEnrichmentRun mocks base method.
+func (m *MockEnrichment) GetEnrichmentRun(ctx context.Context, runID int) (domain.EnrichmentRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEnrichmentRun", ctx, runID)
+	ret0, _ := ret[0].(domain.EnrichmentRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEnrichmentRun indicates an expected call of GetEnrichmentRun.
+func (mr *MockEnrichmentMockRecorder) GetEnrichmentRun(ctx, runID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEnrichmentRun", reflect.TypeOf((*MockEnrichment)(nil).GetEnrichmentRun), ctx, runID)
+}
+
+// Reenrich mocks base method.
+func (m *MockEnrichment) Reenrich(ctx context.Context, filters domain.PersonFiltersQuery, rate float64, force bool) (domain.EnrichmentRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reenrich", ctx, filters, rate, force)
+	ret0, _ := ret[0].(domain.EnrichmentRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reenrich indicates an expected call of Reenrich.
+func (mr *MockEnrichmentMockRecorder) Reenrich(ctx, filters, rate, force interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reenrich", reflect.TypeOf((*MockEnrichment)(nil).Reenrich), ctx, filters, rate, force)
+}
+
+// ResumeEnrichmentRun mocks base method.
+func (m *MockEnrichment) ResumeEnrichmentRun(ctx context.Context, runID int) (domain.EnrichmentRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumeEnrichmentRun", ctx, runID)
+	ret0, _ := ret[0].(domain.EnrichmentRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResumeEnrichmentRun indicates an expected call of ResumeEnrichmentRun.
+func (mr *MockEnrichmentMockRecorder) ResumeEnrichmentRun(ctx, runID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockPerson)(nil).Update), personID, UpdateInput)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeEnrichmentRun", reflect.TypeOf((*MockEnrichment)(nil).ResumeEnrichmentRun), ctx, runID)
 }
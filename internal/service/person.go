@@ -1,12 +1,16 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fio/internal/domain"
 	"fio/internal/repository"
 	"fio/pkg/cache"
 	"fio/pkg/profiler"
 	"fmt"
+	"math"
+	"strconv"
 	"time"
 )
 
@@ -15,26 +19,60 @@ type PersonService struct {
 	nameProfiler profiler.Profiler
 	cache        cache.Cache
 
-	cacheTTL time.Duration
+	cacheTTL   time.Duration
+	enrichment EnrichmentSettings
+	// now stamps the provenance of fields and schedules retries.
+	now func() time.Time
+}
+
+// EnrichmentSettings tune how pending persons are enriched.
+type EnrichmentSettings struct {
+	// MinProbability is the least probability a guessed gender or nationality
+	// needs to be written to the person.
+	MinProbability float64
+	// BatchSize is the number of persons enriched at once.
+	BatchSize int
+	// MaxAttempts is the number of tries before a person is marked as failed.
+	MaxAttempts int
+	// RetryDelay is the wait before the first retry, doubled on every next one
+	// up to MaxRetryDelay.
+	RetryDelay    time.Duration
+	MaxRetryDelay time.Duration
+	// Lease is how long a claimed batch is kept from other workers.
+	Lease time.Duration
+	// ReenrichRate is the number of persons re-enriched a second by a run
+	// started without a rate.
+	ReenrichRate float64
+}
+
+func (e EnrichmentSettings) retryDelay(attempts int) time.Duration {
+	delay := e.RetryDelay
+	for i := 1; i < attempts && delay < e.MaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > e.MaxRetryDelay {
+		delay = e.MaxRetryDelay
+	}
+	return delay
 }
 
 func NewPersonService(personRepo repository.PersonRepo, cache cache.Cache,
-	nameProfiler profiler.Profiler, cacheTTL time.Duration) *PersonService {
+	nameProfiler profiler.Profiler, cacheTTL time.Duration, enrichment EnrichmentSettings) *PersonService {
 	return &PersonService{personRepo: personRepo, cache: cache,
-		nameProfiler: nameProfiler, cacheTTL: cacheTTL}
+		nameProfiler: nameProfiler, cacheTTL: cacheTTL, enrichment: enrichment, now: time.Now}
 }
 
-func (s *PersonService) GetAll(opts domain.PersonsQuery) ([]domain.Person, error) {
+func (s *PersonService) GetAll(ctx context.Context, opts domain.PersonsQuery) ([]domain.Person, error) {
 	var persons []domain.Person
-	redisKey := fmt.Sprintf("getPersons:%v", opts)
-	if value, err := s.cache.Get(redisKey); err == nil {
+	redisKey := s.listKey(ctx, "getPersons:%v", opts)
+	if value, err := s.cache.Get(ctx, redisKey); err == nil {
 		if err = json.Unmarshal(value, &persons); err != nil {
 			return []domain.Person{}, err
 		}
 		return persons, nil
 	}
 
-	persons, err := s.personRepo.GetAll(opts)
+	persons, err := s.personRepo.GetAll(ctx, opts)
 	if err != nil {
 		return []domain.Person{}, err
 	}
@@ -44,36 +82,428 @@ func (s *PersonService) GetAll(opts domain.PersonsQuery) ([]domain.Person, error
 		return []domain.Person{}, err
 	}
 
-	err = s.cache.Set(redisKey, personsBytes, s.cacheTTL)
+	err = s.cache.Set(ctx, redisKey, personsBytes, s.cacheTTL)
 	return persons, err
 }
 
-func (s *PersonService) Add(person domain.Person) (int, error) {
-	age, err := s.nameProfiler.AgifyPerson(person.Name)
+func (s *PersonService) GetAllByCursor(ctx context.Context, opts domain.PersonsCursorQuery) (domain.PersonsConnection, error) {
+	var conn domain.PersonsConnection
+	redisKey := s.listKey(ctx, "getPersonsByCursor:%v", opts)
+	if value, err := s.cache.Get(ctx, redisKey); err == nil {
+		if err = json.Unmarshal(value, &conn); err != nil {
+			return domain.PersonsConnection{}, err
+		}
+		return conn, nil
+	}
+
+	persons, err := s.personRepo.GetAllByCursor(ctx, opts)
+	if err != nil {
+		return domain.PersonsConnection{}, err
+	}
+
+	hasMore := len(persons) > opts.Limit
+	if hasMore {
+		persons = persons[:opts.Limit]
+	}
+	if opts.Backward {
+		for i, j := 0, len(persons)-1; i < j; i, j = i+1, j-1 {
+			persons[i], persons[j] = persons[j], persons[i]
+		}
+		conn.HasPreviousPage = hasMore
+		conn.HasNextPage = opts.Before != nil
+	} else {
+		conn.HasNextPage = hasMore
+		conn.HasPreviousPage = opts.After != nil
+	}
+
+	conn.Persons = persons
+	if len(persons) > 0 {
+		conn.StartCursor = domain.NewCursor(persons[0], opts.Sort).Encode()
+		conn.EndCursor = domain.NewCursor(persons[len(persons)-1], opts.Sort).Encode()
+	}
+
+	connBytes, err := json.Marshal(conn)
+	if err != nil {
+		return domain.PersonsConnection{}, err
+	}
+
+	err = s.cache.Set(ctx, redisKey, connBytes, s.cacheTTL)
+	return conn, err
+}
+
+func (s *PersonService) GetByID(ctx context.Context, personID int) (domain.Person, error) {
+	var person domain.Person
+	redisKey := personKey(personID)
+	if value, err := s.cache.Get(ctx, redisKey); err == nil {
+		if err = json.Unmarshal(value, &person); err != nil {
+			return domain.Person{}, err
+		}
+		return person, nil
+	}
+
+	person, err := s.personRepo.GetByID(ctx, personID)
+	if err != nil {
+		return domain.Person{}, err
+	}
+
+	personBytes, err := json.Marshal(person)
+	if err != nil {
+		return domain.Person{}, err
+	}
+
+	err = s.cache.Set(ctx, redisKey, personBytes, s.cacheTTL)
+	return person, err
+}
+
+func (s *PersonService) Count(ctx context.Context, filters domain.PersonFiltersQuery) (int, error) {
+	var count int
+	redisKey := s.listKey(ctx, "countPersons:%v", filters)
+	if value, err := s.cache.Get(ctx, redisKey); err == nil {
+		if err = json.Unmarshal(value, &count); err != nil {
+			return 0, err
+		}
+		return count, nil
+	}
+
+	count, err := s.personRepo.Count(ctx, filters)
 	if err != nil {
 		return 0, err
 	}
 
-	gender, err := s.nameProfiler.GenderizePerson(person.Name)
+	countBytes, err := json.Marshal(count)
 	if err != nil {
 		return 0, err
 	}
 
-	nationality, err := s.nameProfiler.NationalizePerson(person.Name)
+	err = s.cache.Set(ctx, redisKey, countBytes, s.cacheTTL)
+	return count, err
+}
+
+// Add saves the person as pending, its attributes are filled in later by
+// EnrichPending. So persons are added even while the profiler is down.
+func (s *PersonService) Add(ctx context.Context, person domain.Person) (int, error) {
+	s.prepareNew(&person)
+	personID, err := s.personRepo.Add(ctx, person)
 	if err != nil {
 		return 0, err
 	}
+	s.invalidate(ctx)
+	return personID, nil
+}
+
+// AddBatch saves the persons as pending, all or none. The ids are returned in
+// the order of persons.
+func (s *PersonService) AddBatch(ctx context.Context, persons []domain.Person) ([]int, error) {
+	if len(persons) == 0 || len(persons) > domain.MaxBatchSize {
+		return nil, domain.ErrBatchSize
+	}
 
-	person.Age = age
-	person.Gender = gender
-	person.Nationality = nationality
-	return s.personRepo.Add(person)
+	for i := range persons {
+		s.prepareNew(&persons[i])
+	}
+	personIDs, err := s.personRepo.AddBatch(ctx, persons)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate(ctx)
+	return personIDs, nil
 }
 
-func (s *PersonService) Delete(personID int) (bool, error) {
-	return s.personRepo.Delete(personID)
+// prepareNew leaves the attributes of a new person to enrichment. A person
+// keeping its attributes has the given ones marked as manual, it is only
+// pending if some are missing.
+func (s *PersonService) prepareNew(person *domain.Person) {
+	person.Enrichment = nil
+	person.Provenance = nil
+	person.EnrichmentStatus = domain.EnrichmentPending
+	if !person.KeepAttributes {
+		return
+	}
+
+	age, gender, nationality := person.Age > 0, person.Gender != "", person.Nationality != ""
+	if age || gender || nationality {
+		person.Provenance = domain.NewManualProvenance(age, gender, nationality, s.now())
+	}
+	if age && gender && nationality {
+		person.EnrichmentStatus = domain.EnrichmentComplete
+	}
 }
 
-func (s *PersonService) Update(personID int, input domain.UpdatePersonInput) (bool, error) {
-	return s.personRepo.Update(personID, input)
+// EnrichPending claims a batch of pending persons and enriches them with
+// grouped profiler lookups. If the lookups fail the batch is retried with an
+// exponential backoff, a person out of attempts is marked as failed. The
+// number of claimed persons is returned, zero when none is due.
+func (s *PersonService) EnrichPending(ctx context.Context) (int, error) {
+	jobs, err := s.personRepo.ClaimEnrichment(ctx, s.enrichment.BatchSize, s.enrichment.Lease)
+	if err != nil || len(jobs) == 0 {
+		return 0, err
+	}
+
+	lookups := make([]profiler.Person, len(jobs))
+	for i, job := range jobs {
+		lookups[i] = profilerPerson(job.Person)
+	}
+	profiles, err := s.nameProfiler.ProfileBatch(ctx, lookups)
+	if err != nil {
+		err = profilerError(err)
+		if ctx.Err() != nil {
+			// the lease runs out and the batch is claimed again
+			return len(jobs), err
+		}
+		for _, job := range jobs {
+			if retryErr := s.retryEnrichment(ctx, job, err); retryErr != nil {
+				return len(jobs), retryErr
+			}
+		}
+		return len(jobs), err
+	}
+
+	// a person that can't be saved is retried on its own, the rest of the
+	// batch is saved regardless
+	completed := make([]int, 0, len(jobs))
+	var errs []error
+	for i, job := range jobs {
+		s.enrich(&job.Person, profiles[i], job.Force)
+		if err = s.personRepo.CompleteEnrichment(ctx, job); err != nil {
+			if ctx.Err() != nil {
+				errs = append(errs, err)
+				break
+			}
+			if retryErr := s.retryEnrichment(ctx, job, err); retryErr != nil {
+				err = errors.Join(err, retryErr)
+			}
+			errs = append(errs, fmt.Errorf("failed to save enriched person %d: %w", job.ID, err))
+			continue
+		}
+		completed = append(completed, job.ID)
+	}
+	if len(completed) > 0 {
+		s.invalidate(ctx, completed...)
+	}
+	return len(jobs), errors.Join(errs...)
+}
+
+// Reenrich starts a run enriching the persons matching filters again, all
+// persons if there are no filters. The run is carried out by EnrichPending at
+// rate persons a second, the default rate if zero. It survives restarts and
+// its progress is reported by GetEnrichmentRun. A forced run overwrites the
+// fields an operator set.
+func (s *PersonService) Reenrich(ctx context.Context, filters domain.PersonFiltersQuery, rate float64,
+	force bool) (domain.EnrichmentRun, error) {
+	if rate < 0 || math.IsNaN(rate) || math.IsInf(rate, 0) {
+		return domain.EnrichmentRun{}, domain.ErrInvalidRate
+	}
+	if rate == 0 {
+		rate = s.enrichment.ReenrichRate
+	}
+
+	runID, err := s.personRepo.CreateEnrichmentRun(ctx, filters, rate, force)
+	if err != nil {
+		return domain.EnrichmentRun{}, err
+	}
+	// the persons of the run are pending now, whichever they are
+	s.cache.DeleteByPrefix(ctx, personKeyPrefix) //nolint:errcheck
+	s.invalidate(ctx)
+	return s.personRepo.GetEnrichmentRun(ctx, runID)
+}
+
+func (s *PersonService) GetEnrichmentRun(ctx context.Context, runID int) (domain.EnrichmentRun, error) {
+	return s.personRepo.GetEnrichmentRun(ctx, runID)
+}
+
+// ResumeEnrichmentRun carries on with a run that was stopped, its persons
+// that are still queued are spread out from now on at the rate of the run.
+func (s *PersonService) ResumeEnrichmentRun(ctx context.Context, runID int) (domain.EnrichmentRun, error) {
+	if _, err := s.personRepo.GetEnrichmentRun(ctx, runID); err != nil {
+		return domain.EnrichmentRun{}, err
+	}
+	if err := s.personRepo.ScheduleEnrichmentRun(ctx, runID); err != nil {
+		return domain.EnrichmentRun{}, err
+	}
+	return s.personRepo.GetEnrichmentRun(ctx, runID)
+}
+
+// retryEnrichment puts the job off, or fails it once it is out of attempts.
+func (s *PersonService) retryEnrichment(ctx context.Context, job domain.EnrichmentJob, cause error) error {
+	if job.Attempts >= s.enrichment.MaxAttempts {
+		if err := s.personRepo.FailEnrichment(ctx, job, cause.Error()); err != nil {
+			return err
+		}
+		s.invalidate(ctx, job.ID)
+		return nil
+	}
+	return s.personRepo.RetryEnrichment(ctx, job, s.now().Add(s.enrichment.retryDelay(job.Attempts)), cause.Error())
+}
+
+func (s *PersonService) Delete(ctx context.Context, personID int) (bool, error) {
+	ok, err := affected(s.personRepo.Delete(ctx, personID))
+	if err == nil {
+		s.invalidate(ctx, personID)
+	}
+	return ok, err
+}
+
+// Update marks the age, gender and nationality it sets as manual, so
+// enrichment leaves them alone.
+func (s *PersonService) Update(ctx context.Context, personID int, input domain.UpdatePersonInput) (bool, error) {
+	input.Provenance = nil
+	if input.Age != nil || input.Gender != nil || input.Nationality != nil {
+		input.Provenance = domain.NewManualProvenance(input.Age != nil, input.Gender != nil, input.Nationality != nil, s.now())
+	}
+	ok, err := affected(s.personRepo.Update(ctx, personID, input))
+	if err == nil {
+		s.invalidate(ctx, personID)
+	}
+	return ok, err
+}
+
+// Replace marks age, gender and nationality as manual, so enrichment leaves
+// them alone.
+func (s *PersonService) Replace(ctx context.Context, personID int, person domain.Person) (bool, error) {
+	person.Provenance = domain.NewManualProvenance(true, true, true, s.now())
+	ok, err := affected(s.personRepo.Replace(ctx, personID, person))
+	if err == nil {
+		s.invalidate(ctx, personID)
+	}
+	return ok, err
+}
+
+// profilerPerson is what the profiler may look at to enrich the person.
+func profilerPerson(person domain.Person) profiler.Person {
+	lookup := profiler.Person{Name: person.Name, Surname: person.Surname}
+	if person.Patronymic != nil {
+		lookup.Patronymic = *person.Patronymic
+	}
+	return lookup
+}
+
+// enrich fills in the attributes of the person from the profile and records
+// how confident the profiler was about each value it wrote, the person is then
+// complete. Guesses less likely than minProbability aren't written, neither
+// are fields an operator set unless forced. Such fields keep their value and
+// the metadata recorded when they were written.
+func (s *PersonService) enrich(person *domain.Person, profile profiler.Profile, force bool) {
+	var enrichment domain.Enrichment
+	if person.Enrichment != nil {
+		enrichment = *person.Enrichment
+	}
+	provenance := domain.Provenance{}
+	if person.Provenance != nil {
+		provenance = *person.Provenance
+	}
+	enriched := &domain.FieldProvenance{Source: domain.ProvenanceEnriched, UpdatedAt: s.now()}
+
+	if force || !provenance.Age.IsManual() {
+		person.Age = profile.Age.Age
+		provenance.Age = enriched
+		enrichment.Age = domain.Confidence{Source: profile.Age.Source, Count: profile.Age.Count}
+	}
+
+	guess := profile.Gender
+	if guess.Gender != "" && guess.Probability >= s.enrichment.MinProbability && (force || !provenance.Gender.IsManual()) {
+		person.Gender = guess.Gender
+		provenance.Gender = enriched
+		enrichment.Gender = domain.Confidence{Source: guess.Source, Probability: &guess.Probability, Count: guess.Count}
+	}
+
+	countries := profile.Nationality.Country
+	if len(countries) > 0 && countries[0].Probability >= s.enrichment.MinProbability &&
+		(force || !provenance.Nationality.IsManual()) {
+		person.Nationality = countries[0].CountryID
+		provenance.Nationality = enriched
+		enrichment.Nationality = domain.Confidence{Source: profile.Nationality.Source,
+			Probability: &countries[0].Probability, Count: profile.Nationality.Count}
+		for _, country := range countries[1:] {
+			enrichment.Nationality.Alternatives = append(enrichment.Nationality.Alternatives,
+				domain.Alternative{Value: country.CountryID, Probability: country.Probability})
+		}
+	}
+
+	person.Enrichment = &enrichment
+	person.Provenance = &provenance
+	person.EnrichmentStatus = domain.EnrichmentComplete
+}
+
+// profilerError classifies a failed profiler lookup, context errors are
+// returned as is.
+func profilerError(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	if errors.Is(err, profiler.ErrUpstreamFailed) {
+		return domain.NewError(domain.CodeUpstreamFailed, err)
+	}
+	return domain.NewError(domain.CodeUpstreamUnavailable, err)
+}
+
+// affected maps a write that touched no rows to domain.ErrPersonNotFound.
+func affected(ok bool, err error) (bool, error) {
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		return false, domain.ErrPersonNotFound
+	}
+	return true, nil
+}
+
+// Lists and counts are cached under a versioned namespace, a write moves the
+// version on so the entries of the old one are no longer read and expire.
+// Persons are cached on their own and dropped when they are written.
+const (
+	listVersionKey  = "persons:version"
+	personKeyPrefix = "getPerson:"
+)
+
+func personKey(personID int) string {
+	return fmt.Sprintf("%s%d", personKeyPrefix, personID)
+}
+
+// listKey puts a list or count key under the current version.
+func (s *PersonService) listKey(ctx context.Context, format string, args ...interface{}) string {
+	return fmt.Sprintf("persons:%s:", s.listVersion(ctx)) + fmt.Sprintf(format, args...)
+}
+
+// listVersion returns the current version. A missing one, e.g. evicted by
+// Redis, is seeded rather than started from zero again, which could bring
+// back a version whose lists are still cached.
+func (s *PersonService) listVersion(ctx context.Context) string {
+	if value, err := s.cache.Get(ctx, listVersionKey); err == nil {
+		return string(value)
+	}
+	seed := s.listVersionSeed()
+	seeded, err := s.cache.SetNX(ctx, listVersionKey, seed)
+	if err != nil {
+		return "0"
+	}
+	if seeded {
+		return string(seed)
+	}
+	// seeded or moved on by someone else meanwhile
+	value, err := s.cache.Get(ctx, listVersionKey)
+	if err != nil {
+		return "0"
+	}
+	return string(value)
+}
+
+// listVersionSeed starts a version from the clock, so it is past any version
+// counted up since the previous seed.
+func (s *PersonService) listVersionSeed() []byte {
+	return []byte(strconv.FormatInt(s.now().UnixNano(), 10))
+}
+
+// invalidate drops the cached persons with the given ids and all cached lists
+// and counts. It is best effort, as the write is done by then, entries it
+// misses expire after cacheTTL.
+func (s *PersonService) invalidate(ctx context.Context, personIDs ...int) {
+	keys := make([]string, len(personIDs))
+	for i, personID := range personIDs {
+		keys[i] = personKey(personID)
+	}
+	s.cache.Delete(ctx, keys...) //nolint:errcheck
+	// INCR is atomic, so concurrent invalidations never share a version. It
+	// would start a missing version from zero, so the version is seeded first.
+	s.cache.SetNX(ctx, listVersionKey, s.listVersionSeed()) //nolint:errcheck
+	s.cache.Incr(ctx, listVersionKey)                       //nolint:errcheck
 }
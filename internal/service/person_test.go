@@ -1,13 +1,16 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fio/internal/domain"
 	mock_repository "fio/internal/repository/mocks"
 	mock_cache "fio/pkg/cache/mocks"
+	"fio/pkg/profiler"
 	mock_profiler "fio/pkg/profiler/mocks"
 	"fmt"
+	"math"
 	"testing"
 	"time"
 
@@ -29,10 +32,11 @@ func TestPersonService_GetAll(t *testing.T) {
 			name:      "DB OK",
 			inputOpts: domain.PersonsQuery{},
 			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, t time.Duration, opts domain.PersonsQuery) {
-				c.EXPECT().Get(fmt.Sprintf("getPersons:%v", opts)).Return([]byte{}, errors.New("something went wrong"))
-				rp.EXPECT().GetAll(opts).Return([]domain.Person{{ID: 1, Name: "Test", Surname: "Test", Age: 22, Gender: "male", Nationality: "RU"}}, nil)
+				c.EXPECT().Get(gomock.Any(), "persons:version").Return([]byte("7"), nil)
+				c.EXPECT().Get(gomock.Any(), fmt.Sprintf("persons:7:getPersons:%v", opts)).Return([]byte{}, errors.New("something went wrong"))
+				rp.EXPECT().GetAll(gomock.Any(), opts).Return([]domain.Person{{ID: 1, Name: "Test", Surname: "Test", Age: 22, Gender: "male", Nationality: "RU"}}, nil)
 				personBytes, _ := json.Marshal([]domain.Person{{ID: 1, Name: "Test", Surname: "Test", Age: 22, Gender: "male", Nationality: "RU"}}) //nolint:errcheck
-				c.EXPECT().Set(fmt.Sprintf("getPersons:%v", opts), personBytes, t).Return(nil)
+				c.EXPECT().Set(gomock.Any(), fmt.Sprintf("persons:7:getPersons:%v", opts), personBytes, t).Return(nil)
 			},
 			want: []domain.Person{{ID: 1, Name: "Test", Surname: "Test", Age: 22, Gender: "male", Nationality: "RU"}},
 		},
@@ -41,7 +45,33 @@ func TestPersonService_GetAll(t *testing.T) {
 			inputOpts: domain.PersonsQuery{},
 			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, t time.Duration, opts domain.PersonsQuery) {
 				personBytes, _ := json.Marshal([]domain.Person{{ID: 1, Name: "Test", Surname: "Test", Age: 22, Gender: "male", Nationality: "RU"}})
-				c.EXPECT().Get(fmt.Sprintf("getPersons:%v", opts)).Return(personBytes, nil)
+				c.EXPECT().Get(gomock.Any(), "persons:version").Return([]byte("7"), nil)
+				c.EXPECT().Get(gomock.Any(), fmt.Sprintf("persons:7:getPersons:%v", opts)).Return(personBytes, nil)
+			},
+			want: []domain.Person{{ID: 1, Name: "Test", Surname: "Test", Age: 22, Gender: "male", Nationality: "RU"}},
+		},
+		{
+			name:      "No Version",
+			inputOpts: domain.PersonsQuery{},
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, t time.Duration, opts domain.PersonsQuery) {
+				personBytes, _ := json.Marshal([]domain.Person{{ID: 1, Name: "Test", Surname: "Test", Age: 22, Gender: "male", Nationality: "RU"}})
+				c.EXPECT().Get(gomock.Any(), "persons:version").Return(nil, errors.New("redis: nil"))
+				c.EXPECT().SetNX(gomock.Any(), "persons:version", []byte("1696161600000000000")).Return(true, nil)
+				c.EXPECT().Get(gomock.Any(), fmt.Sprintf("persons:1696161600000000000:getPersons:%v", opts)).Return(personBytes, nil)
+			},
+			want: []domain.Person{{ID: 1, Name: "Test", Surname: "Test", Age: 22, Gender: "male", Nationality: "RU"}},
+		},
+		{
+			name:      "Version Seeded Meanwhile",
+			inputOpts: domain.PersonsQuery{},
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, t time.Duration, opts domain.PersonsQuery) {
+				personBytes, _ := json.Marshal([]domain.Person{{ID: 1, Name: "Test", Surname: "Test", Age: 22, Gender: "male", Nationality: "RU"}})
+				gomock.InOrder(
+					c.EXPECT().Get(gomock.Any(), "persons:version").Return(nil, errors.New("redis: nil")),
+					c.EXPECT().SetNX(gomock.Any(), "persons:version", gomock.Any()).Return(false, nil),
+					c.EXPECT().Get(gomock.Any(), "persons:version").Return([]byte("1696161600000000007"), nil),
+				)
+				c.EXPECT().Get(gomock.Any(), fmt.Sprintf("persons:1696161600000000007:getPersons:%v", opts)).Return(personBytes, nil)
 			},
 			want: []domain.Person{{ID: 1, Name: "Test", Surname: "Test", Age: 22, Gender: "male", Nationality: "RU"}},
 		},
@@ -49,7 +79,8 @@ func TestPersonService_GetAll(t *testing.T) {
 			name:      "Cache Error",
 			inputOpts: domain.PersonsQuery{},
 			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, t time.Duration, opts domain.PersonsQuery) {
-				c.EXPECT().Get(fmt.Sprintf("getPersons:%v", opts)).Return([]byte{1}, nil)
+				c.EXPECT().Get(gomock.Any(), "persons:version").Return([]byte("7"), nil)
+				c.EXPECT().Get(gomock.Any(), fmt.Sprintf("persons:7:getPersons:%v", opts)).Return([]byte{1}, nil)
 			},
 			wantErr: true,
 		},
@@ -57,8 +88,9 @@ func TestPersonService_GetAll(t *testing.T) {
 			name:      "DB Error",
 			inputOpts: domain.PersonsQuery{},
 			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, t time.Duration, opts domain.PersonsQuery) {
-				c.EXPECT().Get(fmt.Sprintf("getPersons:%v", opts)).Return([]byte{}, errors.New("something went wrong"))
-				rp.EXPECT().GetAll(opts).Return(nil, errors.New("something went wrong"))
+				c.EXPECT().Get(gomock.Any(), "persons:version").Return([]byte("7"), nil)
+				c.EXPECT().Get(gomock.Any(), fmt.Sprintf("persons:7:getPersons:%v", opts)).Return([]byte{}, errors.New("something went wrong"))
+				rp.EXPECT().GetAll(gomock.Any(), opts).Return(nil, errors.New("something went wrong"))
 			},
 			wantErr: true,
 		},
@@ -73,9 +105,216 @@ func TestPersonService_GetAll(t *testing.T) {
 		cacheTTL := 30 * time.Second //nolint:gomnd
 		test.mockBehaviour(repoPerson, cache, cacheTTL, test.inputOpts)
 
-		personService := NewPersonService(repoPerson, cache, nil, cacheTTL)
+		personService := NewPersonService(repoPerson, cache, nil, cacheTTL, EnrichmentSettings{})
+		personService.now = func() time.Time { return time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC) }
+
+		got, err := personService.GetAll(context.Background(), test.inputOpts)
+		if test.wantErr {
+			assert.Error(t, err)
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		}
+	}
+}
+
+func TestPersonService_GetAllByCursor(t *testing.T) {
+	type mockBehaviour func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, t time.Duration, opts domain.PersonsCursorQuery)
+
+	first := domain.Person{ID: 1, Name: "First", Surname: "Test", Age: 22, Gender: "male", Nationality: "RU"}
+	second := domain.Person{ID: 2, Name: "Second", Surname: "Test", Age: 23, Gender: "male", Nationality: "RU"}
+	third := domain.Person{ID: 3, Name: "Third", Surname: "Test", Age: 24, Gender: "male", Nationality: "RU"}
+
+	tests := []struct {
+		name          string
+		inputOpts     domain.PersonsCursorQuery
+		mockBehaviour mockBehaviour
+		want          domain.PersonsConnection
+		wantErr       bool
+	}{
+		{
+			name:      "Forward With Next Page",
+			inputOpts: domain.PersonsCursorQuery{CursorQuery: domain.CursorQuery{Limit: 2}},
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, t time.Duration, opts domain.PersonsCursorQuery) {
+				c.EXPECT().Get(gomock.Any(), "persons:version").Return([]byte("7"), nil)
+				c.EXPECT().Get(gomock.Any(), fmt.Sprintf("persons:7:getPersonsByCursor:%v", opts)).Return([]byte{}, errors.New("something went wrong"))
+				rp.EXPECT().GetAllByCursor(gomock.Any(), opts).Return([]domain.Person{first, second, third}, nil)
+				c.EXPECT().Set(gomock.Any(), fmt.Sprintf("persons:7:getPersonsByCursor:%v", opts), gomock.Any(), t).Return(nil)
+			},
+			want: domain.PersonsConnection{
+				Persons:     []domain.Person{first, second},
+				StartCursor: domain.Cursor{ID: 1}.Encode(),
+				EndCursor:   domain.Cursor{ID: 2}.Encode(),
+				HasNextPage: true,
+			},
+		},
+		{
+			name: "Backward Last Page",
+			inputOpts: domain.PersonsCursorQuery{CursorQuery: domain.CursorQuery{
+				Limit: 2, Before: &domain.Cursor{ID: 4}, Backward: true,
+			}},
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, t time.Duration, opts domain.PersonsCursorQuery) {
+				c.EXPECT().Get(gomock.Any(), "persons:version").Return([]byte("7"), nil)
+				c.EXPECT().Get(gomock.Any(), fmt.Sprintf("persons:7:getPersonsByCursor:%v", opts)).Return([]byte{}, errors.New("something went wrong"))
+				rp.EXPECT().GetAllByCursor(gomock.Any(), opts).Return([]domain.Person{third, second}, nil)
+				c.EXPECT().Set(gomock.Any(), fmt.Sprintf("persons:7:getPersonsByCursor:%v", opts), gomock.Any(), t).Return(nil)
+			},
+			want: domain.PersonsConnection{
+				Persons:     []domain.Person{second, third},
+				StartCursor: domain.Cursor{ID: 2}.Encode(),
+				EndCursor:   domain.Cursor{ID: 3}.Encode(),
+				HasNextPage: true,
+			},
+		},
+		{
+			name:      "DB Error",
+			inputOpts: domain.PersonsCursorQuery{CursorQuery: domain.CursorQuery{Limit: 2}},
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, t time.Duration, opts domain.PersonsCursorQuery) {
+				c.EXPECT().Get(gomock.Any(), "persons:version").Return([]byte("7"), nil)
+				c.EXPECT().Get(gomock.Any(), fmt.Sprintf("persons:7:getPersonsByCursor:%v", opts)).Return([]byte{}, errors.New("something went wrong"))
+				rp.EXPECT().GetAllByCursor(gomock.Any(), opts).Return(nil, errors.New("something went wrong"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		c := gomock.NewController(t)
+		defer c.Finish()
+
+		repoPerson := mock_repository.NewMockPersonRepo(c)
+		cache := mock_cache.NewMockCache(c)
+		cacheTTL := 30 * time.Second //nolint:gomnd
+		test.mockBehaviour(repoPerson, cache, cacheTTL, test.inputOpts)
+
+		personService := NewPersonService(repoPerson, cache, nil, cacheTTL, EnrichmentSettings{})
+
+		got, err := personService.GetAllByCursor(context.Background(), test.inputOpts)
+		if test.wantErr {
+			assert.Error(t, err)
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		}
+	}
+}
+
+func TestPersonService_GetByID(t *testing.T) {
+	type mockBehaviour func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, t time.Duration, personID int)
+
+	tests := []struct {
+		name          string
+		inputID       int
+		mockBehaviour mockBehaviour
+		want          domain.Person
+		wantErr       bool
+	}{
+		{
+			name:    "DB OK",
+			inputID: 1,
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, t time.Duration, personID int) {
+				c.EXPECT().Get(gomock.Any(), fmt.Sprintf("getPerson:%d", personID)).Return([]byte{}, errors.New("something went wrong"))
+				rp.EXPECT().GetByID(gomock.Any(), personID).Return(domain.Person{ID: 1, Name: "Test", Surname: "Test", Age: 22, Gender: "male", Nationality: "RU"}, nil)
+				personBytes, _ := json.Marshal(domain.Person{ID: 1, Name: "Test", Surname: "Test", Age: 22, Gender: "male", Nationality: "RU"}) //nolint:errcheck
+				c.EXPECT().Set(gomock.Any(), fmt.Sprintf("getPerson:%d", personID), personBytes, t).Return(nil)
+			},
+			want: domain.Person{ID: 1, Name: "Test", Surname: "Test", Age: 22, Gender: "male", Nationality: "RU"},
+		},
+		{
+			name:    "CacheOK",
+			inputID: 1,
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, t time.Duration, personID int) {
+				personBytes, _ := json.Marshal(domain.Person{ID: 1, Name: "Test", Surname: "Test", Age: 22, Gender: "male", Nationality: "RU"}) //nolint:errcheck
+				c.EXPECT().Get(gomock.Any(), fmt.Sprintf("getPerson:%d", personID)).Return(personBytes, nil)
+			},
+			want: domain.Person{ID: 1, Name: "Test", Surname: "Test", Age: 22, Gender: "male", Nationality: "RU"},
+		},
+		{
+			name:    "Not Found",
+			inputID: 1,
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, t time.Duration, personID int) {
+				c.EXPECT().Get(gomock.Any(), fmt.Sprintf("getPerson:%d", personID)).Return([]byte{}, errors.New("something went wrong"))
+				rp.EXPECT().GetByID(gomock.Any(), personID).Return(domain.Person{}, domain.ErrPersonNotFound)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		c := gomock.NewController(t)
+		defer c.Finish()
+
+		repoPerson := mock_repository.NewMockPersonRepo(c)
+		cache := mock_cache.NewMockCache(c)
+		cacheTTL := 30 * time.Second //nolint:gomnd
+		test.mockBehaviour(repoPerson, cache, cacheTTL, test.inputID)
+
+		personService := NewPersonService(repoPerson, cache, nil, cacheTTL, EnrichmentSettings{})
 
-		got, err := personService.GetAll(test.inputOpts)
+		got, err := personService.GetByID(context.Background(), test.inputID)
+		if test.wantErr {
+			assert.Error(t, err)
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		}
+	}
+}
+
+func TestPersonService_Count(t *testing.T) {
+	type mockBehaviour func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, t time.Duration, filters domain.PersonFiltersQuery)
+
+	tests := []struct {
+		name          string
+		inputFilters  domain.PersonFiltersQuery
+		mockBehaviour mockBehaviour
+		want          int
+		wantErr       bool
+	}{
+		{
+			name:         "DB OK",
+			inputFilters: domain.PersonFiltersQuery{Name: stringPointer("Test")},
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, t time.Duration, filters domain.PersonFiltersQuery) {
+				c.EXPECT().Get(gomock.Any(), "persons:version").Return([]byte("7"), nil)
+				c.EXPECT().Get(gomock.Any(), "persons:7:countPersons:name=Test").Return([]byte{}, errors.New("something went wrong"))
+				rp.EXPECT().Count(gomock.Any(), filters).Return(42, nil)
+				c.EXPECT().Set(gomock.Any(), "persons:7:countPersons:name=Test", []byte("42"), t).Return(nil)
+			},
+			want: 42,
+		},
+		{
+			name:         "CacheOK",
+			inputFilters: domain.PersonFiltersQuery{},
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, t time.Duration, filters domain.PersonFiltersQuery) {
+				c.EXPECT().Get(gomock.Any(), "persons:version").Return([]byte("7"), nil)
+				c.EXPECT().Get(gomock.Any(), "persons:7:countPersons:").Return([]byte("42"), nil)
+			},
+			want: 42,
+		},
+		{
+			name:         "DB Error",
+			inputFilters: domain.PersonFiltersQuery{},
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, t time.Duration, filters domain.PersonFiltersQuery) {
+				c.EXPECT().Get(gomock.Any(), "persons:version").Return([]byte("7"), nil)
+				c.EXPECT().Get(gomock.Any(), "persons:7:countPersons:").Return([]byte{}, errors.New("something went wrong"))
+				rp.EXPECT().Count(gomock.Any(), filters).Return(0, errors.New("something went wrong"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		c := gomock.NewController(t)
+		defer c.Finish()
+
+		repoPerson := mock_repository.NewMockPersonRepo(c)
+		cache := mock_cache.NewMockCache(c)
+		cacheTTL := 30 * time.Second //nolint:gomnd
+		test.mockBehaviour(repoPerson, cache, cacheTTL, test.inputFilters)
+
+		personService := NewPersonService(repoPerson, cache, nil, cacheTTL, EnrichmentSettings{})
+
+		got, err := personService.Count(context.Background(), test.inputFilters)
 		if test.wantErr {
 			assert.Error(t, err)
 		} else {
@@ -86,7 +325,10 @@ func TestPersonService_GetAll(t *testing.T) {
 }
 
 func TestPersonService_Add(t *testing.T) {
-	type mockBehaviour func(rp *mock_repository.MockPersonRepo, np *mock_profiler.MockProfiler, person domain.Person)
+	type mockBehaviour func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, person domain.Person)
+
+	now := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	manual := &domain.FieldProvenance{Source: domain.ProvenanceManual, UpdatedAt: now}
 
 	tests := []struct {
 		name          string
@@ -97,57 +339,366 @@ func TestPersonService_Add(t *testing.T) {
 	}{
 		{
 			name:        "OK",
-			inputPerson: domain.Person{},
-			mockBehaviour: func(rp *mock_repository.MockPersonRepo, np *mock_profiler.MockProfiler, person domain.Person) {
-				np.EXPECT().AgifyPerson(person.Name).Return(6106, nil)
-				np.EXPECT().GenderizePerson(person.Name).Return("blop", nil)
-				np.EXPECT().NationalizePerson(person.Name).Return("blop", nil)
-				person.Age = 6106
-				person.Gender = "blop"
-				person.Nationality = "blop"
-				rp.EXPECT().Add(person).Return(1, nil)
+			inputPerson: domain.Person{Name: "Dmitriy", Surname: "Ushakov", Patronymic: stringPointer("Vasilevich")},
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, person domain.Person) {
+				person.EnrichmentStatus = domain.EnrichmentPending
+				rp.EXPECT().Add(gomock.Any(), person).Return(1, nil)
+				expectInvalidation(c)
+			},
+			want: 1,
+		},
+		{
+			name: "Enrichment Ignored On Input",
+			inputPerson: domain.Person{Name: "Dmitriy", Surname: "Ushakov",
+				Enrichment: &domain.Enrichment{}, EnrichmentStatus: domain.EnrichmentComplete},
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, person domain.Person) {
+				rp.EXPECT().Add(gomock.Any(), domain.Person{Name: "Dmitriy", Surname: "Ushakov",
+					EnrichmentStatus: domain.EnrichmentPending}).Return(1, nil)
+				expectInvalidation(c)
+			},
+			want: 1,
+		},
+		{
+			name: "Attributes Kept",
+			inputPerson: domain.Person{Name: "Dmitriy", Surname: "Ushakov", Age: 22, Gender: "male", Nationality: "RU",
+				KeepAttributes: true},
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, person domain.Person) {
+				person.EnrichmentStatus = domain.EnrichmentComplete
+				person.Provenance = &domain.Provenance{Age: manual, Gender: manual, Nationality: manual}
+				rp.EXPECT().Add(gomock.Any(), person).Return(1, nil)
+				expectInvalidation(c)
+			},
+			want: 1,
+		},
+		{
+			name:        "Missing Attributes Enriched",
+			inputPerson: domain.Person{Name: "Dmitriy", Surname: "Ushakov", Gender: "male", KeepAttributes: true},
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, person domain.Person) {
+				person.EnrichmentStatus = domain.EnrichmentPending
+				person.Provenance = &domain.Provenance{Gender: manual}
+				rp.EXPECT().Add(gomock.Any(), person).Return(1, nil)
+				expectInvalidation(c)
+			},
+			want: 1,
+		},
+		{
+			name:        "Attributes Not Kept",
+			inputPerson: domain.Person{Name: "Dmitriy", Surname: "Ushakov", Age: 22, Gender: "male", Nationality: "RU"},
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, person domain.Person) {
+				person.EnrichmentStatus = domain.EnrichmentPending
+				rp.EXPECT().Add(gomock.Any(), person).Return(1, nil)
+				expectInvalidation(c)
 			},
 			want: 1,
 		},
 		{
 			name:        "DB Error",
 			inputPerson: domain.Person{},
-			mockBehaviour: func(rp *mock_repository.MockPersonRepo, np *mock_profiler.MockProfiler, person domain.Person) {
-				np.EXPECT().AgifyPerson(person.Name).Return(6106, nil)
-				np.EXPECT().GenderizePerson(person.Name).Return("blop", nil)
-				np.EXPECT().NationalizePerson(person.Name).Return("blop", nil)
-				person.Age = 6106
-				person.Gender = "blop"
-				person.Nationality = "blop"
-				rp.EXPECT().Add(person).Return(0, errors.New("something went wrong"))
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, person domain.Person) {
+				rp.EXPECT().Add(gomock.Any(), gomock.Any()).Return(0, errors.New("something went wrong"))
 			},
 			wantErr: true,
 		},
+	}
+
+	for _, test := range tests {
+		c := gomock.NewController(t)
+		defer c.Finish()
+
+		repoPerson := mock_repository.NewMockPersonRepo(c)
+		cache := mock_cache.NewMockCache(c)
+		test.mockBehaviour(repoPerson, cache, test.inputPerson)
+
+		personService := NewPersonService(repoPerson, cache, nil, 0, EnrichmentSettings{}) //nolint:gomnd
+		personService.now = func() time.Time { return now }
+
+		got, err := personService.Add(context.Background(), test.inputPerson)
+		if test.wantErr {
+			assert.Error(t, err)
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		}
+	}
+}
+
+func TestPersonService_AddBatch(t *testing.T) {
+	type mockBehaviour func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, persons []domain.Person)
+
+	tests := []struct {
+		name          string
+		inputPersons  []domain.Person
+		mockBehaviour mockBehaviour
+		want          []int
+		wantErr       bool
+		wantCode      domain.ErrorCode
+	}{
 		{
-			name:        "Agify Error",
-			inputPerson: domain.Person{},
-			mockBehaviour: func(rp *mock_repository.MockPersonRepo, np *mock_profiler.MockProfiler, person domain.Person) {
-				np.EXPECT().AgifyPerson(person.Name).Return(0, errors.New("something went wrong"))
+			name:         "OK",
+			inputPersons: []domain.Person{{Name: "Dmitriy"}, {Name: "Anna"}},
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, persons []domain.Person) {
+				rp.EXPECT().AddBatch(gomock.Any(), []domain.Person{
+					{Name: "Dmitriy", EnrichmentStatus: domain.EnrichmentPending},
+					{Name: "Anna", EnrichmentStatus: domain.EnrichmentPending},
+				}).Return([]int{1, 2}, nil)
+				expectInvalidation(c)
+			},
+			want: []int{1, 2},
+		},
+		{
+			name:          "Empty Batch",
+			inputPersons:  []domain.Person{},
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, persons []domain.Person) {},
+			wantErr:       true,
+			wantCode:      domain.CodeValidation,
+		},
+		{
+			name:         "DB Error",
+			inputPersons: []domain.Person{{Name: "Dmitriy"}},
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, persons []domain.Person) {
+				rp.EXPECT().AddBatch(gomock.Any(), gomock.Any()).Return(nil, errors.New("something went wrong"))
+			},
+			wantErr:  true,
+			wantCode: domain.CodeInternal,
+		},
+	}
+
+	for _, test := range tests {
+		c := gomock.NewController(t)
+		defer c.Finish()
+
+		repoPerson := mock_repository.NewMockPersonRepo(c)
+		cache := mock_cache.NewMockCache(c)
+		test.mockBehaviour(repoPerson, cache, test.inputPersons)
+
+		personService := NewPersonService(repoPerson, cache, nil, 0, EnrichmentSettings{}) //nolint:gomnd
+
+		got, err := personService.AddBatch(context.Background(), test.inputPersons)
+		if test.wantErr {
+			assert.Error(t, err)
+			assert.Equal(t, test.wantCode, domain.CodeOf(err))
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		}
+	}
+}
+
+func TestPersonService_EnrichPending(t *testing.T) {
+	type mockBehaviour func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, np *mock_profiler.MockProfiler)
+
+	settings := EnrichmentSettings{BatchSize: 10, MaxAttempts: 3, RetryDelay: time.Second, MaxRetryDelay: time.Minute, Lease: time.Minute}
+
+	agify := profiler.AgifyResponse{Age: 42, Count: 1200, Source: "remote"}
+	genderize := profiler.GenderizeResponse{Gender: "male", Probability: 0.99, Count: 1500, Source: "morphology"}
+	nationalize := profiler.NationalizeResponse{Count: 900, Source: "remote", Country: []profiler.Country{
+		{CountryID: "RU", Probability: 0.4},
+		{CountryID: "UA", Probability: 0.3},
+	}}
+	pending := domain.Person{ID: 1, Name: "Dmitriy", Surname: "Ushakov", Patronymic: stringPointer("Vasilevich"),
+		EnrichmentStatus: domain.EnrichmentPending}
+	lookup := profiler.Person{Name: "Dmitriy", Surname: "Ushakov", Patronymic: "Vasilevich"}
+	now := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	enriched := &domain.FieldProvenance{Source: domain.ProvenanceEnriched, UpdatedAt: now}
+	manual := &domain.FieldProvenance{Source: domain.ProvenanceManual, UpdatedAt: now.Add(-time.Hour)}
+	edited := pending
+	edited.Age = 30
+	edited.Provenance = &domain.Provenance{Age: manual}
+	edited.Enrichment = &domain.Enrichment{Age: domain.Confidence{Source: "remote", Count: 800}}
+
+	tests := []struct {
+		name           string
+		minProbability float64
+		mockBehaviour  mockBehaviour
+		want           int
+		wantErr        bool
+	}{
+		{
+			name: "OK",
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, np *mock_profiler.MockProfiler) {
+				rp.EXPECT().ClaimEnrichment(gomock.Any(), 10, time.Minute).Return([]domain.EnrichmentJob{{Person: pending, RunID: intPointer(3), Attempts: 1}}, nil)
+				np.EXPECT().ProfileBatch(gomock.Any(), []profiler.Person{lookup}).
+					Return([]profiler.Profile{{Age: agify, Gender: genderize, Nationality: nationalize}}, nil)
+				person := pending
+				person.Age = 42
+				person.Gender = "male"
+				person.Nationality = "RU"
+				person.EnrichmentStatus = domain.EnrichmentComplete
+				person.Enrichment = &domain.Enrichment{
+					Age:    domain.Confidence{Source: "remote", Count: 1200},
+					Gender: domain.Confidence{Source: "morphology", Probability: float64Pointer(0.99), Count: 1500},
+					Nationality: domain.Confidence{Source: "remote", Probability: float64Pointer(0.4), Count: 900,
+						Alternatives: []domain.Alternative{{Value: "UA", Probability: 0.3}}},
+				}
+				person.Provenance = &domain.Provenance{Age: enriched, Gender: enriched, Nationality: enriched}
+				rp.EXPECT().CompleteEnrichment(gomock.Any(), domain.EnrichmentJob{Person: person, RunID: intPointer(3), Attempts: 1}).Return(nil)
+				expectInvalidation(c, 1)
+			},
+			want: 1,
+		},
+		{
+			name:           "Below Min Probability",
+			minProbability: 0.5,
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, np *mock_profiler.MockProfiler) {
+				rp.EXPECT().ClaimEnrichment(gomock.Any(), 10, time.Minute).Return([]domain.EnrichmentJob{{Person: pending, Attempts: 1}}, nil)
+				np.EXPECT().ProfileBatch(gomock.Any(), []profiler.Person{lookup}).
+					Return([]profiler.Profile{{Age: agify, Gender: genderize, Nationality: nationalize}}, nil)
+				person := pending
+				person.Age = 42
+				person.Gender = "male"
+				person.EnrichmentStatus = domain.EnrichmentComplete
+				person.Enrichment = &domain.Enrichment{
+					Age:    domain.Confidence{Source: "remote", Count: 1200},
+					Gender: domain.Confidence{Source: "morphology", Probability: float64Pointer(0.99), Count: 1500},
+				}
+				person.Provenance = &domain.Provenance{Age: enriched, Gender: enriched}
+				rp.EXPECT().CompleteEnrichment(gomock.Any(), domain.EnrichmentJob{Person: person, Attempts: 1}).Return(nil)
+				expectInvalidation(c, 1)
+			},
+			want: 1,
+		},
+		{
+			name:           "Below Min Probability Keeps Earlier Value",
+			minProbability: 0.5,
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, np *mock_profiler.MockProfiler) {
+				earlier := &domain.FieldProvenance{Source: domain.ProvenanceEnriched, UpdatedAt: now.Add(-time.Hour)}
+				reenriched := pending
+				reenriched.Nationality = "UA"
+				reenriched.Enrichment = &domain.Enrichment{Nationality: domain.Confidence{Source: "remote", Probability: float64Pointer(0.7), Count: 300}}
+				reenriched.Provenance = &domain.Provenance{Nationality: earlier}
+				rp.EXPECT().ClaimEnrichment(gomock.Any(), 10, time.Minute).Return([]domain.EnrichmentJob{{Person: reenriched, Attempts: 1}}, nil)
+				np.EXPECT().ProfileBatch(gomock.Any(), []profiler.Person{lookup}).
+					Return([]profiler.Profile{{Age: agify, Nationality: nationalize}}, nil)
+				person := reenriched
+				person.Age = 42
+				person.EnrichmentStatus = domain.EnrichmentComplete
+				person.Enrichment = &domain.Enrichment{
+					Age:         domain.Confidence{Source: "remote", Count: 1200},
+					Nationality: domain.Confidence{Source: "remote", Probability: float64Pointer(0.7), Count: 300},
+				}
+				person.Provenance = &domain.Provenance{Age: enriched, Nationality: earlier}
+				rp.EXPECT().CompleteEnrichment(gomock.Any(), domain.EnrichmentJob{Person: person, Attempts: 1}).Return(nil)
+				expectInvalidation(c, 1)
+			},
+			want: 1,
+		},
+		{
+			name: "Manual Field Kept",
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, np *mock_profiler.MockProfiler) {
+				rp.EXPECT().ClaimEnrichment(gomock.Any(), 10, time.Minute).Return([]domain.EnrichmentJob{{Person: edited, Attempts: 1}}, nil)
+				np.EXPECT().ProfileBatch(gomock.Any(), []profiler.Person{lookup}).
+					Return([]profiler.Profile{{Age: agify, Gender: genderize}}, nil)
+				person := edited
+				person.Gender = "male"
+				person.EnrichmentStatus = domain.EnrichmentComplete
+				person.Enrichment = &domain.Enrichment{
+					Age:    domain.Confidence{Source: "remote", Count: 800},
+					Gender: domain.Confidence{Source: "morphology", Probability: float64Pointer(0.99), Count: 1500},
+				}
+				person.Provenance = &domain.Provenance{Age: manual, Gender: enriched}
+				rp.EXPECT().CompleteEnrichment(gomock.Any(), domain.EnrichmentJob{Person: person, Attempts: 1}).Return(nil)
+				expectInvalidation(c, 1)
+			},
+			want: 1,
+		},
+		{
+			name: "Forced",
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, np *mock_profiler.MockProfiler) {
+				rp.EXPECT().ClaimEnrichment(gomock.Any(), 10, time.Minute).Return([]domain.EnrichmentJob{{Person: edited, Attempts: 1, Force: true}}, nil)
+				np.EXPECT().ProfileBatch(gomock.Any(), []profiler.Person{lookup}).
+					Return([]profiler.Profile{{Age: agify, Gender: genderize}}, nil)
+				person := edited
+				person.Age = 42
+				person.Gender = "male"
+				person.EnrichmentStatus = domain.EnrichmentComplete
+				person.Enrichment = &domain.Enrichment{
+					Age:    domain.Confidence{Source: "remote", Count: 1200},
+					Gender: domain.Confidence{Source: "morphology", Probability: float64Pointer(0.99), Count: 1500},
+				}
+				person.Provenance = &domain.Provenance{Age: enriched, Gender: enriched}
+				rp.EXPECT().CompleteEnrichment(gomock.Any(), domain.EnrichmentJob{Person: person, Attempts: 1, Force: true}).Return(nil)
+				expectInvalidation(c, 1)
+			},
+			want: 1,
+		},
+		{
+			name: "Nothing Due",
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, np *mock_profiler.MockProfiler) {
+				rp.EXPECT().ClaimEnrichment(gomock.Any(), 10, time.Minute).Return(nil, nil)
+			},
+			want: 0,
+		},
+		{
+			name: "Claim Error",
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, np *mock_profiler.MockProfiler) {
+				rp.EXPECT().ClaimEnrichment(gomock.Any(), 10, time.Minute).Return(nil, errors.New("something went wrong"))
 			},
 			wantErr: true,
 		},
 		{
-			name:        "Genderize Error",
-			inputPerson: domain.Person{},
-			mockBehaviour: func(rp *mock_repository.MockPersonRepo, np *mock_profiler.MockProfiler, person domain.Person) {
-				np.EXPECT().AgifyPerson(person.Name).Return(6106, nil)
-				np.EXPECT().GenderizePerson(person.Name).Return("", errors.New("something went wrong"))
+			name: "Profiler Unavailable Retried",
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, np *mock_profiler.MockProfiler) {
+				rp.EXPECT().ClaimEnrichment(gomock.Any(), 10, time.Minute).Return([]domain.EnrichmentJob{{Person: pending, Attempts: 2}}, nil)
+				np.EXPECT().ProfileBatch(gomock.Any(), []profiler.Person{lookup}).Return(nil, profiler.ErrUnavailable)
+				rp.EXPECT().RetryEnrichment(gomock.Any(), claimOf(1, 2), now.Add(2*time.Second), gomock.Any()).Return(nil)
 			},
+			want:    1,
 			wantErr: true,
 		},
 		{
-			name:        "Nationalize Error",
-			inputPerson: domain.Person{},
-			mockBehaviour: func(rp *mock_repository.MockPersonRepo, np *mock_profiler.MockProfiler, person domain.Person) {
-				np.EXPECT().AgifyPerson(person.Name).Return(6106, nil)
-				np.EXPECT().GenderizePerson(person.Name).Return("blop", nil)
-				np.EXPECT().NationalizePerson(person.Name).Return("", errors.New("something went wrong"))
+			name: "Out Of Attempts",
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, np *mock_profiler.MockProfiler) {
+				rp.EXPECT().ClaimEnrichment(gomock.Any(), 10, time.Minute).Return([]domain.EnrichmentJob{{Person: pending, Attempts: 3}}, nil)
+				np.EXPECT().ProfileBatch(gomock.Any(), []profiler.Person{lookup}).Return(nil, profiler.ErrUnavailable)
+				rp.EXPECT().FailEnrichment(gomock.Any(), claimOf(1, 3), gomock.Any()).Return(nil)
+				expectInvalidation(c, 1)
+			},
+			want:    1,
+			wantErr: true,
+		},
+		{
+			name: "Complete Error Retried Alone",
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, np *mock_profiler.MockProfiler) {
+				other := domain.Person{ID: 2, Name: "Anna", EnrichmentStatus: domain.EnrichmentPending}
+				rp.EXPECT().ClaimEnrichment(gomock.Any(), 10, time.Minute).
+					Return([]domain.EnrichmentJob{{Person: pending, Attempts: 1}, {Person: other, Attempts: 1}}, nil)
+				np.EXPECT().ProfileBatch(gomock.Any(), []profiler.Person{lookup, {Name: "Anna"}}).
+					Return([]profiler.Profile{{}, {}}, nil)
+				rp.EXPECT().CompleteEnrichment(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, job domain.EnrichmentJob) error {
+						if job.ID == 1 {
+							return errors.New("something went wrong")
+						}
+						return nil
+					}).Times(2)
+				rp.EXPECT().RetryEnrichment(gomock.Any(), claimOf(1, 1), now.Add(time.Second), "something went wrong").Return(nil)
+				expectInvalidation(c, 2)
 			},
+			want:    2,
+			wantErr: true,
+		},
+		{
+			name: "Complete Error Out Of Attempts",
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, np *mock_profiler.MockProfiler) {
+				rp.EXPECT().ClaimEnrichment(gomock.Any(), 10, time.Minute).Return([]domain.EnrichmentJob{{Person: pending, Attempts: 3}}, nil)
+				np.EXPECT().ProfileBatch(gomock.Any(), []profiler.Person{lookup}).Return([]profiler.Profile{{}}, nil)
+				rp.EXPECT().CompleteEnrichment(gomock.Any(), gomock.Any()).Return(errors.New("something went wrong"))
+				rp.EXPECT().FailEnrichment(gomock.Any(), claimOf(1, 3), "something went wrong").Return(nil)
+				expectInvalidation(c, 1)
+			},
+			want:    1,
+			wantErr: true,
+		},
+		{
+			name: "Complete And Retry Error",
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, np *mock_profiler.MockProfiler) {
+				rp.EXPECT().ClaimEnrichment(gomock.Any(), 10, time.Minute).Return([]domain.EnrichmentJob{{Person: pending, Attempts: 1}}, nil)
+				np.EXPECT().ProfileBatch(gomock.Any(), []profiler.Person{lookup}).Return([]profiler.Profile{{}}, nil)
+				rp.EXPECT().CompleteEnrichment(gomock.Any(), gomock.Any()).Return(errors.New("something went wrong"))
+				rp.EXPECT().RetryEnrichment(gomock.Any(), claimOf(1, 1), now.Add(time.Second), "something went wrong").
+					Return(errors.New("database is down"))
+			},
+			want:    1,
 			wantErr: true,
 		},
 	}
@@ -157,14 +708,156 @@ func TestPersonService_Add(t *testing.T) {
 		defer c.Finish()
 
 		repoPerson := mock_repository.NewMockPersonRepo(c)
+		cache := mock_cache.NewMockCache(c)
 		nameProfiler := mock_profiler.NewMockProfiler(c)
-		test.mockBehaviour(repoPerson, nameProfiler, test.inputPerson)
+		test.mockBehaviour(repoPerson, cache, nameProfiler)
 
-		personService := NewPersonService(repoPerson, nil, nameProfiler, 0) //nolint:gomnd
+		enrichment := settings
+		enrichment.MinProbability = test.minProbability
+		personService := NewPersonService(repoPerson, cache, nameProfiler, 0, enrichment) //nolint:gomnd
+		personService.now = func() time.Time { return now }
 
-		got, err := personService.Add(test.inputPerson)
+		got, err := personService.EnrichPending(context.Background())
 		if test.wantErr {
 			assert.Error(t, err)
+		} else {
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, test.want, got)
+	}
+}
+
+func TestPersonService_Reenrich(t *testing.T) {
+	type mockBehaviour func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, filters domain.PersonFiltersQuery)
+
+	filters := domain.PersonFiltersQuery{Gender: stringPointer("male")}
+	run := domain.EnrichmentRun{ID: 3, Filters: "gender=male", Rate: 5, Total: 10, Pending: 10}
+
+	tests := []struct {
+		name          string
+		inputRate     float64
+		mockBehaviour mockBehaviour
+		want          domain.EnrichmentRun
+		wantErr       bool
+		wantCode      domain.ErrorCode
+	}{
+		{
+			name:      "OK",
+			inputRate: 5,
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, filters domain.PersonFiltersQuery) {
+				rp.EXPECT().CreateEnrichmentRun(gomock.Any(), filters, 5.0, false).Return(3, nil)
+				c.EXPECT().DeleteByPrefix(gomock.Any(), "getPerson:").Return(nil)
+				expectInvalidation(c)
+				rp.EXPECT().GetEnrichmentRun(gomock.Any(), 3).Return(run, nil)
+			},
+			want: run,
+		},
+		{
+			name: "Default Rate",
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, filters domain.PersonFiltersQuery) {
+				rp.EXPECT().CreateEnrichmentRun(gomock.Any(), filters, 2.0, false).Return(3, nil)
+				c.EXPECT().DeleteByPrefix(gomock.Any(), "getPerson:").Return(nil)
+				expectInvalidation(c)
+				rp.EXPECT().GetEnrichmentRun(gomock.Any(), 3).Return(run, nil)
+			},
+			want: run,
+		},
+		{
+			name:          "Negative Rate",
+			inputRate:     -1,
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, filters domain.PersonFiltersQuery) {},
+			wantErr:       true,
+			wantCode:      domain.CodeValidation,
+		},
+		{
+			name:          "NaN Rate",
+			inputRate:     math.NaN(),
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, filters domain.PersonFiltersQuery) {},
+			wantErr:       true,
+			wantCode:      domain.CodeValidation,
+		},
+		{
+			name:          "Infinite Rate",
+			inputRate:     math.Inf(1),
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, filters domain.PersonFiltersQuery) {},
+			wantErr:       true,
+			wantCode:      domain.CodeValidation,
+		},
+		{
+			name:      "DB Error",
+			inputRate: 5,
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, filters domain.PersonFiltersQuery) {
+				rp.EXPECT().CreateEnrichmentRun(gomock.Any(), filters, 5.0, false).Return(0, errors.New("something went wrong"))
+			},
+			wantErr:  true,
+			wantCode: domain.CodeInternal,
+		},
+	}
+
+	for _, test := range tests {
+		c := gomock.NewController(t)
+		defer c.Finish()
+
+		repoPerson := mock_repository.NewMockPersonRepo(c)
+		cache := mock_cache.NewMockCache(c)
+		test.mockBehaviour(repoPerson, cache, filters)
+
+		personService := NewPersonService(repoPerson, cache, nil, 0, EnrichmentSettings{ReenrichRate: 2}) //nolint:gomnd
+
+		got, err := personService.Reenrich(context.Background(), filters, test.inputRate, false)
+		if test.wantErr {
+			assert.Error(t, err)
+			assert.Equal(t, test.wantCode, domain.CodeOf(err))
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		}
+	}
+}
+
+func TestPersonService_ResumeEnrichmentRun(t *testing.T) {
+	type mockBehaviour func(rp *mock_repository.MockPersonRepo, runID int)
+
+	run := domain.EnrichmentRun{ID: 3, Rate: 5, Total: 10, Done: 6, Pending: 4}
+
+	tests := []struct {
+		name          string
+		inputID       int
+		mockBehaviour mockBehaviour
+		want          domain.EnrichmentRun
+		wantErr       error
+	}{
+		{
+			name:    "OK",
+			inputID: 3,
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, runID int) {
+				rp.EXPECT().GetEnrichmentRun(gomock.Any(), runID).Return(run, nil).Times(2)
+				rp.EXPECT().ScheduleEnrichmentRun(gomock.Any(), runID).Return(nil)
+			},
+			want: run,
+		},
+		{
+			name:    "Not Found",
+			inputID: 3,
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, runID int) {
+				rp.EXPECT().GetEnrichmentRun(gomock.Any(), runID).Return(domain.EnrichmentRun{}, domain.ErrEnrichmentRunNotFound)
+			},
+			wantErr: domain.ErrEnrichmentRunNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		c := gomock.NewController(t)
+		defer c.Finish()
+
+		repoPerson := mock_repository.NewMockPersonRepo(c)
+		test.mockBehaviour(repoPerson, test.inputID)
+
+		personService := NewPersonService(repoPerson, nil, nil, 0, EnrichmentSettings{}) //nolint:gomnd
+
+		got, err := personService.ResumeEnrichmentRun(context.Background(), test.inputID)
+		if test.wantErr != nil {
+			assert.ErrorIs(t, err, test.wantErr)
 		} else {
 			assert.NoError(t, err)
 			assert.Equal(t, test.want, got)
@@ -173,7 +866,7 @@ func TestPersonService_Add(t *testing.T) {
 }
 
 func TestPersonService_Delete(t *testing.T) {
-	type mockBehaviour func(rp *mock_repository.MockPersonRepo, personID int)
+	type mockBehaviour func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, personID int)
 
 	tests := []struct {
 		name          string
@@ -185,24 +878,25 @@ func TestPersonService_Delete(t *testing.T) {
 		{
 			name:    "OK",
 			inputID: 1,
-			mockBehaviour: func(rp *mock_repository.MockPersonRepo, personID int) {
-				rp.EXPECT().Delete(personID).Return(true, nil)
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, personID int) {
+				rp.EXPECT().Delete(gomock.Any(), personID).Return(true, nil)
+				expectInvalidation(c, personID)
 			},
 			want: true,
 		},
 		{
-			name:    "Not deleted",
+			name:    "Not found",
 			inputID: 1,
-			mockBehaviour: func(rp *mock_repository.MockPersonRepo, personID int) {
-				rp.EXPECT().Delete(personID).Return(false, nil)
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, personID int) {
+				rp.EXPECT().Delete(gomock.Any(), personID).Return(false, nil)
 			},
-			want: false,
+			wantErr: true,
 		},
 		{
 			name:    "DB Error",
 			inputID: 1,
-			mockBehaviour: func(rp *mock_repository.MockPersonRepo, personID int) {
-				rp.EXPECT().Delete(personID).Return(false, errors.New("something went wrong"))
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, personID int) {
+				rp.EXPECT().Delete(gomock.Any(), personID).Return(false, errors.New("something went wrong"))
 			},
 			wantErr: true,
 		},
@@ -213,11 +907,12 @@ func TestPersonService_Delete(t *testing.T) {
 		defer c.Finish()
 
 		repoPerson := mock_repository.NewMockPersonRepo(c)
-		test.mockBehaviour(repoPerson, test.inputID)
+		cache := mock_cache.NewMockCache(c)
+		test.mockBehaviour(repoPerson, cache, test.inputID)
 
-		personService := NewPersonService(repoPerson, nil, nil, 0) //nolint:gomnd
+		personService := NewPersonService(repoPerson, cache, nil, 0, EnrichmentSettings{}) //nolint:gomnd
 
-		got, err := personService.Delete(test.inputID)
+		got, err := personService.Delete(context.Background(), test.inputID)
 		if test.wantErr {
 			assert.Error(t, err)
 		} else {
@@ -228,7 +923,9 @@ func TestPersonService_Delete(t *testing.T) {
 }
 
 func TestPersonService_Update(t *testing.T) {
-	type mockBehaviour func(rp *mock_repository.MockPersonRepo, personID int, updatePerson domain.UpdatePersonInput)
+	type mockBehaviour func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, personID int, updatePerson domain.UpdatePersonInput)
+
+	now := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
 
 	tests := []struct {
 		name             string
@@ -242,26 +939,98 @@ func TestPersonService_Update(t *testing.T) {
 			name:             "OK",
 			inputID:          1,
 			inputUpdateInput: domain.UpdatePersonInput{},
-			mockBehaviour: func(rp *mock_repository.MockPersonRepo, personID int, updatePerson domain.UpdatePersonInput) {
-				rp.EXPECT().Update(personID, updatePerson).Return(true, nil)
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, personID int, updatePerson domain.UpdatePersonInput) {
+				rp.EXPECT().Update(gomock.Any(), personID, updatePerson).Return(true, nil)
+				expectInvalidation(c, personID)
 			},
 			want: true,
 		},
 		{
-			name:             "Not updated",
+			name:             "Manual Fields",
+			inputID:          1,
+			inputUpdateInput: domain.UpdatePersonInput{Name: stringPointer("Alexey"), Age: intPointer(22), Nationality: stringPointer("RU")},
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, personID int, updatePerson domain.UpdatePersonInput) {
+				updatePerson.Provenance = &domain.Provenance{
+					Age:         &domain.FieldProvenance{Source: domain.ProvenanceManual, UpdatedAt: now},
+					Nationality: &domain.FieldProvenance{Source: domain.ProvenanceManual, UpdatedAt: now},
+				}
+				rp.EXPECT().Update(gomock.Any(), personID, updatePerson).Return(true, nil)
+				expectInvalidation(c, personID)
+			},
+			want: true,
+		},
+		{
+			name:             "Not found",
 			inputID:          1,
 			inputUpdateInput: domain.UpdatePersonInput{},
-			mockBehaviour: func(rp *mock_repository.MockPersonRepo, personID int, updatePerson domain.UpdatePersonInput) {
-				rp.EXPECT().Update(personID, updatePerson).Return(false, nil)
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, personID int, updatePerson domain.UpdatePersonInput) {
+				rp.EXPECT().Update(gomock.Any(), personID, updatePerson).Return(false, nil)
 			},
-			want: false,
+			wantErr: true,
 		},
 		{
 			name:             "DB Error",
 			inputID:          1,
 			inputUpdateInput: domain.UpdatePersonInput{},
-			mockBehaviour: func(rp *mock_repository.MockPersonRepo, personID int, updatePerson domain.UpdatePersonInput) {
-				rp.EXPECT().Update(personID, updatePerson).Return(false, errors.New("something went wrong"))
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, personID int, updatePerson domain.UpdatePersonInput) {
+				rp.EXPECT().Update(gomock.Any(), personID, updatePerson).Return(false, errors.New("something went wrong"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		c := gomock.NewController(t)
+		defer c.Finish()
+
+		repoPerson := mock_repository.NewMockPersonRepo(c)
+		cache := mock_cache.NewMockCache(c)
+		test.mockBehaviour(repoPerson, cache, test.inputID, test.inputUpdateInput)
+
+		personService := NewPersonService(repoPerson, cache, nil, 0, EnrichmentSettings{}) //nolint:gomnd
+		personService.now = func() time.Time { return now }
+
+		got, err := personService.Update(context.Background(), test.inputID, test.inputUpdateInput)
+		if test.wantErr {
+			assert.Error(t, err)
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		}
+	}
+}
+
+func TestPersonService_Replace(t *testing.T) {
+	type mockBehaviour func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, personID int, person domain.Person)
+
+	now := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	manual := &domain.FieldProvenance{Source: domain.ProvenanceManual, UpdatedAt: now}
+
+	tests := []struct {
+		name          string
+		inputID       int
+		inputPerson   domain.Person
+		mockBehaviour mockBehaviour
+		want          bool
+		wantErr       bool
+	}{
+		{
+			name:        "OK",
+			inputID:     1,
+			inputPerson: domain.Person{Name: "Dmitriy", Surname: "Ushakov", Age: 22, Gender: "male", Nationality: "RU"},
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, personID int, person domain.Person) {
+				person.Provenance = &domain.Provenance{Age: manual, Gender: manual, Nationality: manual}
+				rp.EXPECT().Replace(gomock.Any(), personID, person).Return(true, nil)
+				expectInvalidation(c, personID)
+			},
+			want: true,
+		},
+		{
+			name:        "Not found",
+			inputID:     1,
+			inputPerson: domain.Person{Name: "Dmitriy", Surname: "Ushakov", Age: 22, Gender: "male", Nationality: "RU"},
+			mockBehaviour: func(rp *mock_repository.MockPersonRepo, c *mock_cache.MockCache, personID int, person domain.Person) {
+				rp.EXPECT().Replace(gomock.Any(), personID, gomock.Any()).Return(false, nil)
 			},
 			wantErr: true,
 		},
@@ -272,11 +1041,13 @@ func TestPersonService_Update(t *testing.T) {
 		defer c.Finish()
 
 		repoPerson := mock_repository.NewMockPersonRepo(c)
-		test.mockBehaviour(repoPerson, test.inputID, test.inputUpdateInput)
+		cache := mock_cache.NewMockCache(c)
+		test.mockBehaviour(repoPerson, cache, test.inputID, test.inputPerson)
 
-		personService := NewPersonService(repoPerson, nil, nil, 0) //nolint:gomnd
+		personService := NewPersonService(repoPerson, cache, nil, 0, EnrichmentSettings{}) //nolint:gomnd
+		personService.now = func() time.Time { return now }
 
-		got, err := personService.Update(test.inputID, test.inputUpdateInput)
+		got, err := personService.Replace(context.Background(), test.inputID, test.inputPerson)
 		if test.wantErr {
 			assert.Error(t, err)
 		} else {
@@ -285,3 +1056,35 @@ func TestPersonService_Update(t *testing.T) {
 		}
 	}
 }
+
+// expectInvalidation expects the cached persons with the ids and all cached
+// lists and counts to be dropped.
+func expectInvalidation(c *mock_cache.MockCache, personIDs ...int) {
+	keys := make([]interface{}, len(personIDs))
+	for i, personID := range personIDs {
+		keys[i] = fmt.Sprintf("getPerson:%d", personID)
+	}
+	c.EXPECT().Delete(gomock.Any(), keys...).Return(nil)
+	c.EXPECT().SetNX(gomock.Any(), "persons:version", gomock.Any()).Return(false, nil)
+	c.EXPECT().Incr(gomock.Any(), "persons:version").Return(int64(8), nil)
+}
+
+// claimOf matches the job claimed for the person on the given attempt.
+func claimOf(personID, attempts int) gomock.Matcher {
+	return gomock.Cond(func(x interface{}) bool {
+		job, ok := x.(domain.EnrichmentJob)
+		return ok && job.ID == personID && job.Attempts == attempts
+	})
+}
+
+func stringPointer(s string) *string {
+	return &s
+}
+
+func intPointer(i int) *int {
+	return &i
+}
+
+func float64Pointer(f float64) *float64 {
+	return &f
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"fio/internal/domain"
 	"fio/internal/repository"
 	"fio/pkg/cache"
@@ -9,14 +10,29 @@ import (
 )
 
 type Person interface {
-	GetAll(opts domain.PersonsQuery) ([]domain.Person, error)
-	Add(person domain.Person) (int, error)
-	Delete(personID int) (bool, error)
-	Update(personID int, UpdateInput domain.UpdatePersonInput) (bool, error)
+	GetAll(ctx context.Context, opts domain.PersonsQuery) ([]domain.Person, error)
+	GetAllByCursor(ctx context.Context, opts domain.PersonsCursorQuery) (domain.PersonsConnection, error)
+	GetByID(ctx context.Context, personID int) (domain.Person, error)
+	Count(ctx context.Context, filters domain.PersonFiltersQuery) (int, error)
+	Add(ctx context.Context, person domain.Person) (int, error)
+	AddBatch(ctx context.Context, persons []domain.Person) ([]int, error)
+	Delete(ctx context.Context, personID int) (bool, error)
+	Update(ctx context.Context, personID int, UpdateInput domain.UpdatePersonInput) (bool, error)
+	Replace(ctx context.Context, personID int, person domain.Person) (bool, error)
+}
+
+// Enrichment fills in the attributes of persons saved as pending and
+// re-enriches existing persons.
+type Enrichment interface {
+	EnrichPending(ctx context.Context) (int, error)
+	Reenrich(ctx context.Context, filters domain.PersonFiltersQuery, rate float64, force bool) (domain.EnrichmentRun, error)
+	GetEnrichmentRun(ctx context.Context, runID int) (domain.EnrichmentRun, error)
+	ResumeEnrichmentRun(ctx context.Context, runID int) (domain.EnrichmentRun, error)
 }
 
 type Service struct {
 	Person
+	Enrichment
 }
 
 type Dependencies struct {
@@ -24,10 +40,13 @@ type Dependencies struct {
 	Cache        cache.Cache
 	NameProfiler profiler.Profiler
 	CacheTTL     time.Duration
+	Enrichment   EnrichmentSettings
 }
 
 func NewService(deps Dependencies) *Service {
+	personService := NewPersonService(deps.Repos, deps.Cache, deps.NameProfiler, deps.CacheTTL, deps.Enrichment)
 	return &Service{
-		Person: NewPersonService(deps.Repos, deps.Cache, deps.NameProfiler, deps.CacheTTL),
+		Person:     personService,
+		Enrichment: personService,
 	}
 }
@@ -0,0 +1,73 @@
+package worker
+
+import (
+	"context"
+	"fio/internal/service"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// EnrichmentPool runs workers that enrich the persons saved as pending.
+type EnrichmentPool struct {
+	enrichment service.Enrichment
+	logger     *zap.SugaredLogger
+
+	workers int
+	// interval is the wait of an idle worker before it looks for due persons again.
+	interval time.Duration
+}
+
+func NewEnrichmentPool(enrichment service.Enrichment, logger *zap.SugaredLogger, workers int, interval time.Duration) *EnrichmentPool {
+	return &EnrichmentPool{
+		enrichment: enrichment,
+		logger:     logger,
+		workers:    workers,
+		interval:   interval,
+	}
+}
+
+// Run starts the workers and returns once ctx is cancelled and all of them
+// have stopped. Batches cut short are claimed again when their lease is over.
+func (p *EnrichmentPool) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < p.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// work enriches batch after batch while there are due persons and waits for
+// interval when there are none or enrichment failed.
+func (p *EnrichmentPool) work(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		claimed, err := p.enrichment.EnrichPending(ctx)
+		if ctx.Err() != nil {
+			// the timer may fire along with ctx, no batch is claimed after it
+			return
+		}
+		if err != nil {
+			p.logger.Errorf("Error occurred while enriching persons: %s", err.Error())
+		}
+
+		wait := p.interval
+		if claimed > 0 && err == nil {
+			wait = 0
+		}
+		timer.Reset(wait)
+	}
+}
@@ -0,0 +1,138 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	mock_service "fio/internal/service/mocks"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// runPool runs the pool until ctx is cancelled, the channel is closed once
+// Run returns.
+func runPool(ctx context.Context, pool *EnrichmentPool) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pool.Run(ctx)
+	}()
+	return done
+}
+
+func assertStopped(t *testing.T, done <-chan struct{}) {
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("pool didn't stop")
+	}
+}
+
+func TestEnrichmentPool_WaitsIntervalWhenIdle(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	const interval = 50 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls []time.Time
+	enrichment := mock_service.NewMockEnrichment(c)
+	enrichment.EXPECT().EnrichPending(gomock.Any()).DoAndReturn(func(context.Context) (int, error) {
+		calls = append(calls, time.Now())
+		if len(calls) == 3 {
+			cancel()
+		}
+		return 0, nil
+	}).Times(3)
+
+	done := runPool(ctx, NewEnrichmentPool(enrichment, zap.NewNop().Sugar(), 1, interval))
+	assertStopped(t, done)
+
+	for i := 1; i < len(calls); i++ {
+		assert.GreaterOrEqual(t, calls[i].Sub(calls[i-1]), interval)
+	}
+}
+
+func TestEnrichmentPool_KeepsGoingWhileBusy(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int
+	enrichment := mock_service.NewMockEnrichment(c)
+	enrichment.EXPECT().EnrichPending(gomock.Any()).DoAndReturn(func(context.Context) (int, error) {
+		calls++
+		if calls == 5 {
+			cancel()
+		}
+		return 10, nil
+	}).Times(5)
+
+	// a busy worker doesn't wait for the interval between batches
+	done := runPool(ctx, NewEnrichmentPool(enrichment, zap.NewNop().Sugar(), 1, time.Hour))
+	assertStopped(t, done)
+}
+
+func TestEnrichmentPool_FailureLoggedAndRetried(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	const interval = 50 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls []time.Time
+	enrichment := mock_service.NewMockEnrichment(c)
+	enrichment.EXPECT().EnrichPending(gomock.Any()).DoAndReturn(func(context.Context) (int, error) {
+		calls = append(calls, time.Now())
+		if len(calls) == 1 {
+			// one person of the batch failed to save
+			return 10, errors.New("failed to save enriched person 3: something went wrong")
+		}
+		cancel()
+		return 10, nil
+	}).Times(2)
+
+	core, logs := observer.New(zap.ErrorLevel)
+	done := runPool(ctx, NewEnrichmentPool(enrichment, zap.New(core).Sugar(), 1, interval))
+	assertStopped(t, done)
+
+	assert.Equal(t, 1, logs.Len())
+	assert.Contains(t, logs.All()[0].Message, "failed to save enriched person 3")
+	// the next batch is only claimed after the interval
+	assert.GreaterOrEqual(t, calls[1].Sub(calls[0]), interval)
+}
+
+func TestEnrichmentPool_StopsOnCancel(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	const workers = 3
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var started sync.WaitGroup
+	started.Add(workers)
+	enrichment := mock_service.NewMockEnrichment(c)
+	enrichment.EXPECT().EnrichPending(gomock.Any()).DoAndReturn(func(ctx context.Context) (int, error) {
+		started.Done()
+		<-ctx.Done()
+		return 10, ctx.Err()
+	}).Times(workers)
+
+	core, logs := observer.New(zap.ErrorLevel)
+	done := runPool(ctx, NewEnrichmentPool(enrichment, zap.New(core).Sugar(), workers, time.Hour))
+
+	started.Wait()
+	cancel()
+	assertStopped(t, done)
+	// batches cut short by the shutdown aren't errors
+	assert.Equal(t, 0, logs.Len())
+}
@@ -1,8 +1,21 @@
 package cache
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 type Cache interface {
-	Set(key string, value []byte, ttl time.Duration) error
-	Get(key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Delete drops the keys, missing ones are skipped.
+	Delete(ctx context.Context, keys ...string) error
+	// DeleteByPrefix drops every key starting with prefix.
+	DeleteByPrefix(ctx context.Context, prefix string) error
+	// SetNX sets the key unless it exists and tells whether it did. The key
+	// never expires.
+	SetNX(ctx context.Context, key string, value []byte) (bool, error)
+	// Incr adds one to the counter at key and returns it, a missing counter
+	// starts from zero. The counter never expires.
+	Incr(ctx context.Context, key string) (int64, error)
 }
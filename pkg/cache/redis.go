@@ -1,7 +1,9 @@
 package cache
 
 import (
+	"context"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis"
@@ -9,6 +11,12 @@ import (
 
 var ErrItemNotFound = errors.New("cache: item not found")
 
+// scanCount is the number of keys DeleteByPrefix asks for per SCAN call.
+const scanCount = 100
+
+// globEscaper keeps the glob characters of a prefix from matching other keys.
+var globEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
+
 type RedisCache struct {
 	rdb *redis.Client
 }
@@ -19,14 +27,65 @@ func NewRedisCache(rdb *redis.Client) *RedisCache {
 	}
 }
 
-func (c *RedisCache) Set(key string, value []byte, ttl time.Duration) error {
-	if _, err := c.rdb.Set(key, value, ttl).Result(); err != nil {
+func (c *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if _, err := c.rdb.WithContext(ctx).Set(key, value, ttl).Result(); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (c *RedisCache) Get(key string) ([]byte, error) {
-	return c.rdb.Get(key).Bytes()
+func (c *RedisCache) Get(ctx context.Context, key string) ([]byte, error) {
+	return c.rdb.WithContext(ctx).Get(key).Bytes()
+}
+
+func (c *RedisCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return c.rdb.WithContext(ctx).Del(keys...).Err()
+}
+
+func (c *RedisCache) SetNX(ctx context.Context, key string, value []byte) (bool, error) {
+	return c.rdb.WithContext(ctx).SetNX(key, value, 0).Result()
+}
+
+func (c *RedisCache) Incr(ctx context.Context, key string) (int64, error) {
+	return c.rdb.WithContext(ctx).Incr(key).Result()
+}
+
+// DeleteByPrefix walks the keys with SCAN rather than KEYS, so Redis isn't
+// blocked on a large keyspace. Keys set meanwhile may be missed. The keys are
+// deleted once the walk is over, in batches of scanCount, so the deletes can't
+// make the walk skip keys on servers that page SCAN by offset.
+func (c *RedisCache) DeleteByPrefix(ctx context.Context, prefix string) error {
+	rdb := c.rdb.WithContext(ctx)
+	match := globEscaper.Replace(prefix) + "*"
+
+	var (
+		matched []string
+		cursor  uint64
+	)
+	for {
+		keys, next, err := rdb.Scan(cursor, match, scanCount).Result()
+		if err != nil {
+			return err
+		}
+		matched = append(matched, keys...)
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+
+	for start := 0; start < len(matched); start += scanCount {
+		end := start + scanCount
+		if end > len(matched) {
+			end = len(matched)
+		}
+		if err := rdb.Del(matched[start:end]...).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,171 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCache(t *testing.T) (*RedisCache, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() }) //nolint:errcheck
+	return NewRedisCache(rdb), mr
+}
+
+func TestRedisCache_Delete(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     []string
+		wantLeft []string
+	}{
+		{
+			name:     "OK",
+			keys:     []string{"getPerson:1", "getPerson:2"},
+			wantLeft: []string{"getPerson:3"},
+		},
+		{
+			name:     "Missing Keys Skipped",
+			keys:     []string{"getPerson:1", "getPerson:4"},
+			wantLeft: []string{"getPerson:2", "getPerson:3"},
+		},
+		{
+			name:     "No Keys",
+			wantLeft: []string{"getPerson:1", "getPerson:2", "getPerson:3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, mr := newTestCache(t)
+			for _, key := range []string{"getPerson:1", "getPerson:2", "getPerson:3"} {
+				assert.NoError(t, mr.Set(key, "{}"))
+			}
+
+			assert.NoError(t, c.Delete(context.Background(), tt.keys...))
+			assert.Equal(t, tt.wantLeft, mr.Keys())
+		})
+	}
+}
+
+func TestRedisCache_DeleteByPrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefix   string
+		keys     []string
+		wantLeft []string
+	}{
+		{
+			name:     "OK",
+			prefix:   "getPerson:",
+			keys:     []string{"getPerson:1", "getPerson:2", "getPersons:1", "persons:7:list", "profiler:agify:anna"},
+			wantLeft: []string{"getPersons:1", "persons:7:list", "profiler:agify:anna"},
+		},
+		{
+			name:     "Star Escaped",
+			prefix:   "a*",
+			keys:     []string{"a*1", "ab1", "a"},
+			wantLeft: []string{"a", "ab1"},
+		},
+		{
+			name:     "Question Mark Escaped",
+			prefix:   "a?",
+			keys:     []string{"a?1", "ab1"},
+			wantLeft: []string{"ab1"},
+		},
+		{
+			name:     "Brackets Escaped",
+			prefix:   "a[bc]",
+			keys:     []string{"a[bc]1", "ab1", "ac1"},
+			wantLeft: []string{"ab1", "ac1"},
+		},
+		{
+			name:     "Backslash Escaped",
+			prefix:   `a\*`,
+			keys:     []string{`a\*1`, "a*1"},
+			wantLeft: []string{"a*1"},
+		},
+		{
+			name:     "Nothing Matches",
+			prefix:   "getPerson:",
+			keys:     []string{"persons:version"},
+			wantLeft: []string{"persons:version"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, mr := newTestCache(t)
+			for _, key := range tt.keys {
+				assert.NoError(t, mr.Set(key, "{}"))
+			}
+
+			assert.NoError(t, c.DeleteByPrefix(context.Background(), tt.prefix))
+			assert.Equal(t, tt.wantLeft, mr.Keys())
+		})
+	}
+}
+
+func TestRedisCache_DeleteByPrefix_ManyPages(t *testing.T) {
+	c, mr := newTestCache(t)
+
+	// more keys than a single SCAN call returns
+	var others []string
+	for i := 0; i < 3*scanCount+7; i++ {
+		assert.NoError(t, mr.Set(fmt.Sprintf("getPerson:%d", i), "{}"))
+		other := fmt.Sprintf("persons:%d:list", i)
+		assert.NoError(t, mr.Set(other, "[]"))
+		others = append(others, other)
+	}
+
+	assert.NoError(t, c.DeleteByPrefix(context.Background(), "getPerson:"))
+	sort.Strings(others)
+	assert.Equal(t, others, mr.Keys())
+}
+
+func TestRedisCache_DeleteByPrefix_Error(t *testing.T) {
+	c, mr := newTestCache(t)
+	mr.Close()
+
+	assert.Error(t, c.DeleteByPrefix(context.Background(), "getPerson:"))
+}
+
+func TestRedisCache_SetNX(t *testing.T) {
+	c, mr := newTestCache(t)
+
+	ok, err := c.SetNX(context.Background(), "persons:version", []byte("100"))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// an existing key is kept
+	ok, err = c.SetNX(context.Background(), "persons:version", []byte("200"))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	got, err := c.Incr(context.Background(), "persons:version")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(101), got)
+	assert.Equal(t, time.Duration(0), mr.TTL("persons:version"))
+}
+
+func TestRedisCache_Incr(t *testing.T) {
+	c, mr := newTestCache(t)
+
+	for want := int64(1); want <= 3; want++ {
+		got, err := c.Incr(context.Background(), "persons:version")
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	// the counter is read back like any other value
+	value, err := c.Get(context.Background(), "persons:version")
+	assert.NoError(t, err)
+	assert.Equal(t, "3", string(value))
+	assert.Equal(t, time.Duration(0), mr.TTL("persons:version"))
+}
@@ -0,0 +1,205 @@
+package profiler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the upstream while its breaker is open.
+var ErrCircuitOpen = errors.New("profiler: circuit open")
+
+type BreakerState int
+
+const (
+	StateClosed BreakerState = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// BreakerSettings configures the breaker of every upstream.
+type BreakerSettings struct {
+	// FailureThreshold is the number of failures in a row that opens the breaker.
+	FailureThreshold int
+	// OpenTimeout is how long the breaker fails fast before letting a probe through.
+	OpenTimeout time.Duration
+	// OnStateChange is called on every transition, e.g. to log it.
+	OnStateChange func(upstream string, from, to BreakerState)
+}
+
+type circuitBreaker struct {
+	upstream string
+	settings BreakerSettings
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// allow reports whether a request may go to the upstream. In the half-open
+// state only a single probe is let through at a time.
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if time.Since(b.openedAt) < b.settings.OpenTimeout {
+			return ErrCircuitOpen
+		}
+		b.setState(StateHalfOpen)
+		b.probing = true
+		return nil
+	case StateHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// done records the outcome of a request let through by allow. Only a success
+// or a 4xx shows the upstream is healthy, any other error is a failure, e.g.
+// an answer that can't be decoded.
+func (b *circuitBreaker) done(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return
+	}
+	if err == nil || errors.Is(err, errRejected) {
+		b.failures = 0
+		if b.state != StateClosed {
+			b.setState(StateClosed)
+		}
+		return
+	}
+
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.settings.FailureThreshold {
+		b.openedAt = time.Now()
+		b.setState(StateOpen)
+	}
+}
+
+func (b *circuitBreaker) currentState() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+func (b *circuitBreaker) setState(state BreakerState) {
+	if b.state == state {
+		return
+	}
+	from := b.state
+	b.state = state
+	if b.settings.OnStateChange != nil {
+		b.settings.OnStateChange(b.upstream, from, state)
+	}
+}
+
+// BreakerProfiler guards every upstream of the wrapped profiler with its own
+// circuit breaker, so an outage fails fast instead of waiting for timeouts.
+type BreakerProfiler struct {
+	profiler    Profiler
+	agify       *circuitBreaker
+	genderize   *circuitBreaker
+	nationalize *circuitBreaker
+}
+
+func NewBreakerProfiler(profiler Profiler, settings BreakerSettings) *BreakerProfiler {
+	if settings.FailureThreshold < 1 {
+		settings.FailureThreshold = 1
+	}
+	return &BreakerProfiler{
+		profiler:    profiler,
+		agify:       &circuitBreaker{upstream: "agify", settings: settings},
+		genderize:   &circuitBreaker{upstream: "genderize", settings: settings},
+		nationalize: &circuitBreaker{upstream: "nationalize", settings: settings},
+	}
+}
+
+func (p *BreakerProfiler) AgifyPerson(ctx context.Context, person Person) (AgifyResponse, error) {
+	if err := p.agify.allow(); err != nil {
+		return AgifyResponse{}, err
+	}
+	age, err := p.profiler.AgifyPerson(ctx, person)
+	p.agify.done(err)
+	return age, err
+}
+
+func (p *BreakerProfiler) GenderizePerson(ctx context.Context, person Person) (GenderizeResponse, error) {
+	if err := p.genderize.allow(); err != nil {
+		return GenderizeResponse{}, err
+	}
+	gender, err := p.profiler.GenderizePerson(ctx, person)
+	p.genderize.done(err)
+	return gender, err
+}
+
+func (p *BreakerProfiler) NationalizePerson(ctx context.Context, person Person) (NationalizeResponse, error) {
+	if err := p.nationalize.allow(); err != nil {
+		return NationalizeResponse{}, err
+	}
+	nationality, err := p.profiler.NationalizePerson(ctx, person)
+	p.nationalize.done(err)
+	return nationality, err
+}
+
+func (p *BreakerProfiler) AgifyBatch(ctx context.Context, persons []Person) ([]AgifyResponse, error) {
+	if err := p.agify.allow(); err != nil {
+		return nil, err
+	}
+	ages, err := p.profiler.AgifyBatch(ctx, persons)
+	p.agify.done(err)
+	return ages, err
+}
+
+func (p *BreakerProfiler) GenderizeBatch(ctx context.Context, persons []Person) ([]GenderizeResponse, error) {
+	if err := p.genderize.allow(); err != nil {
+		return nil, err
+	}
+	genders, err := p.profiler.GenderizeBatch(ctx, persons)
+	p.genderize.done(err)
+	return genders, err
+}
+
+func (p *BreakerProfiler) NationalizeBatch(ctx context.Context, persons []Person) ([]NationalizeResponse, error) {
+	if err := p.nationalize.allow(); err != nil {
+		return nil, err
+	}
+	nationalities, err := p.profiler.NationalizeBatch(ctx, persons)
+	p.nationalize.done(err)
+	return nationalities, err
+}
+
+func (p *BreakerProfiler) ProfileBatch(ctx context.Context, persons []Person) ([]Profile, error) {
+	return profileBatch(ctx, p, persons)
+}
+
+// States returns the current breaker state of every upstream.
+func (p *BreakerProfiler) States() map[string]string {
+	return map[string]string{
+		p.agify.upstream:       p.agify.currentState().String(),
+		p.genderize.upstream:   p.genderize.currentState().String(),
+		p.nationalize.upstream: p.nationalize.currentState().String(),
+	}
+}
@@ -0,0 +1,169 @@
+package profiler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stubProfiler answers age lookups with agify and knows nothing else.
+type stubProfiler struct {
+	unknownProfiler
+	agify func(ctx context.Context, person Person) (AgifyResponse, error)
+}
+
+func (p stubProfiler) AgifyPerson(ctx context.Context, person Person) (AgifyResponse, error) {
+	return p.agify(ctx, person)
+}
+
+func newTestBreaker(threshold int, transitions *[]string) *circuitBreaker {
+	return &circuitBreaker{upstream: "agify", settings: BreakerSettings{
+		FailureThreshold: threshold,
+		OpenTimeout:      time.Minute,
+		OnStateChange: func(upstream string, from, to BreakerState) {
+			*transitions = append(*transitions, fmt.Sprintf("%s:%s->%s", upstream, from, to))
+		},
+	}}
+}
+
+// openTimeoutOver makes the breaker act as if it had been open for OpenTimeout.
+func (b *circuitBreaker) openTimeoutOver() {
+	b.openedAt = time.Now().Add(-b.settings.OpenTimeout)
+}
+
+func TestCircuitBreaker_OpensAfterThreshold(t *testing.T) {
+	var transitions []string
+	b := newTestBreaker(3, &transitions)
+
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, b.allow())
+		b.done(ErrUnavailable)
+	}
+	assert.Equal(t, StateClosed, b.currentState())
+
+	// a success in between starts counting over
+	assert.NoError(t, b.allow())
+	b.done(nil)
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, b.allow())
+		b.done(ErrRateLimited)
+	}
+	assert.Equal(t, StateClosed, b.currentState())
+
+	assert.NoError(t, b.allow())
+	b.done(ErrUnavailable)
+	assert.Equal(t, StateOpen, b.currentState())
+	assert.ErrorIs(t, b.allow(), ErrCircuitOpen)
+	assert.Equal(t, []string{"agify:closed->open"}, transitions)
+}
+
+func TestCircuitBreaker_MalformedAnswersCounted(t *testing.T) {
+	var transitions []string
+	b := newTestBreaker(2, &transitions)
+
+	// a 4xx in between starts counting over, an answer that can't be used doesn't
+	assert.NoError(t, b.allow())
+	b.done(ErrUnavailable)
+	assert.NoError(t, b.allow())
+	b.done(fmt.Errorf("%w: %w: 400", ErrUpstreamFailed, errRejected))
+	assert.NoError(t, b.allow())
+	b.done(fmt.Errorf("%w: error while decoding", ErrUpstreamFailed))
+	assert.Equal(t, StateClosed, b.currentState())
+
+	assert.NoError(t, b.allow())
+	b.done(fmt.Errorf("%w: got 1 answers for 2 names", ErrUpstreamFailed))
+	assert.Equal(t, StateOpen, b.currentState())
+	assert.Equal(t, []string{"agify:closed->open"}, transitions)
+}
+
+func TestCircuitBreaker_ContextErrorsNotCounted(t *testing.T) {
+	var transitions []string
+	b := newTestBreaker(1, &transitions)
+
+	assert.NoError(t, b.allow())
+	b.done(context.Canceled)
+	assert.NoError(t, b.allow())
+	b.done(fmt.Errorf("lookup: %w", context.DeadlineExceeded))
+
+	assert.Equal(t, StateClosed, b.currentState())
+	assert.Empty(t, transitions)
+}
+
+func TestCircuitBreaker_HalfOpen(t *testing.T) {
+	tests := []struct {
+		name        string
+		probeErr    error
+		want        BreakerState
+		transitions []string
+	}{
+		{
+			name:        "Probe Succeeds",
+			want:        StateClosed,
+			transitions: []string{"agify:closed->open", "agify:open->half-open", "agify:half-open->closed"},
+		},
+		{
+			name:        "Probe Gets Client Error",
+			probeErr:    fmt.Errorf("%w: %w: 404", ErrUpstreamFailed, errRejected),
+			want:        StateClosed,
+			transitions: []string{"agify:closed->open", "agify:open->half-open", "agify:half-open->closed"},
+		},
+		{
+			name:        "Probe Gets Malformed Answer",
+			probeErr:    fmt.Errorf("%w: error while decoding", ErrUpstreamFailed),
+			want:        StateOpen,
+			transitions: []string{"agify:closed->open", "agify:open->half-open", "agify:half-open->open"},
+		},
+		{
+			name:        "Probe Fails",
+			probeErr:    ErrUnavailable,
+			want:        StateOpen,
+			transitions: []string{"agify:closed->open", "agify:open->half-open", "agify:half-open->open"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var transitions []string
+			b := newTestBreaker(1, &transitions)
+			assert.NoError(t, b.allow())
+			b.done(ErrUnavailable)
+			assert.ErrorIs(t, b.allow(), ErrCircuitOpen)
+
+			b.openTimeoutOver()
+			assert.NoError(t, b.allow())
+			assert.Equal(t, StateHalfOpen, b.currentState())
+			// a single probe at a time
+			assert.ErrorIs(t, b.allow(), ErrCircuitOpen)
+
+			b.done(tt.probeErr)
+			assert.Equal(t, tt.want, b.currentState())
+			assert.Equal(t, tt.transitions, transitions)
+		})
+	}
+}
+
+func TestBreakerProfiler_FailsFast(t *testing.T) {
+	var calls int
+	upstream := stubProfiler{agify: func(context.Context, Person) (AgifyResponse, error) {
+		calls++
+		return AgifyResponse{}, ErrUnavailable
+	}}
+	p := NewBreakerProfiler(upstream, BreakerSettings{FailureThreshold: 2, OpenTimeout: time.Minute})
+
+	for i := 0; i < 2; i++ {
+		_, err := p.AgifyPerson(context.Background(), Person{Name: "Dmitriy"})
+		assert.ErrorIs(t, err, ErrUnavailable)
+	}
+	_, err := p.AgifyPerson(context.Background(), Person{Name: "Dmitriy"})
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, 2, calls)
+
+	// the other upstreams have breakers of their own
+	assert.Equal(t, map[string]string{"agify": "open", "genderize": "closed", "nationalize": "closed"}, p.States())
+	_, err = p.GenderizePerson(context.Background(), Person{Name: "Dmitriy"})
+	assert.NoError(t, err)
+}
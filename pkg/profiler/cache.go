@@ -0,0 +1,183 @@
+package profiler
+
+import (
+	"context"
+	"encoding/json"
+	"fio/pkg/cache"
+	"strings"
+	"time"
+)
+
+// CachingProfiler remembers the answers of the wrapped profiler per name.
+// Names the upstream knows nothing about are remembered for negativeTTL.
+// A zero TTL turns the matching caching off.
+type CachingProfiler struct {
+	profiler    Profiler
+	cache       cache.Cache
+	ttl         time.Duration
+	negativeTTL time.Duration
+}
+
+func NewCachingProfiler(profiler Profiler, cache cache.Cache, ttl, negativeTTL time.Duration) *CachingProfiler {
+	return &CachingProfiler{
+		profiler:    profiler,
+		cache:       cache,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+	}
+}
+
+// NewCacheOnlyProfiler answers from what a CachingProfiler sharing the cache
+// remembered and never asks an upstream. Names missing from the cache are
+// answered as unknown.
+func NewCacheOnlyProfiler(cache cache.Cache) *CachingProfiler {
+	return NewCachingProfiler(unknownProfiler{}, cache, 0, 0)
+}
+
+func (p *CachingProfiler) AgifyPerson(ctx context.Context, person Person) (AgifyResponse, error) {
+	return cached(ctx, p, "agify", person, p.profiler.AgifyPerson)
+}
+
+func (p *CachingProfiler) GenderizePerson(ctx context.Context, person Person) (GenderizeResponse, error) {
+	return cached(ctx, p, "genderize", person, p.profiler.GenderizePerson)
+}
+
+func (p *CachingProfiler) NationalizePerson(ctx context.Context, person Person) (NationalizeResponse, error) {
+	return cached(ctx, p, "nationalize", person, p.profiler.NationalizePerson)
+}
+
+func (p *CachingProfiler) AgifyBatch(ctx context.Context, persons []Person) ([]AgifyResponse, error) {
+	return cachedBatch(ctx, p, "agify", persons, p.profiler.AgifyBatch)
+}
+
+func (p *CachingProfiler) GenderizeBatch(ctx context.Context, persons []Person) ([]GenderizeResponse, error) {
+	return cachedBatch(ctx, p, "genderize", persons, p.profiler.GenderizeBatch)
+}
+
+func (p *CachingProfiler) NationalizeBatch(ctx context.Context, persons []Person) ([]NationalizeResponse, error) {
+	return cachedBatch(ctx, p, "nationalize", persons, p.profiler.NationalizeBatch)
+}
+
+func (p *CachingProfiler) ProfileBatch(ctx context.Context, persons []Person) ([]Profile, error) {
+	return profileBatch(ctx, p, persons)
+}
+
+// cached returns the remembered answer or asks fetch and remembers its answer.
+// Cache failures only cost a lookup, they are never returned.
+func cached[T answer](ctx context.Context, p *CachingProfiler, upstream string, person Person,
+	fetch func(context.Context, Person) (T, error)) (T, error) {
+	key := cacheKey(upstream, person.Name)
+	if value, ok := recall[T](ctx, p, key); ok {
+		return value, nil
+	}
+
+	value, err := fetch(ctx, person)
+	if err != nil {
+		return value, err
+	}
+	remember(ctx, p, key, value)
+	return value, nil
+}
+
+// cachedBatch is cached for batch lookups, only the persons missing from the
+// cache are passed to fetch.
+func cachedBatch[T answer](ctx context.Context, p *CachingProfiler, upstream string, persons []Person,
+	fetch func(context.Context, []Person) ([]T, error)) ([]T, error) {
+	values := make([]T, len(persons))
+	missing := make([]int, 0, len(persons))
+	for i, person := range persons {
+		value, ok := recall[T](ctx, p, cacheKey(upstream, person.Name))
+		if !ok {
+			missing = append(missing, i)
+			continue
+		}
+		values[i] = value
+	}
+	if len(missing) == 0 {
+		return values, nil
+	}
+
+	missingPersons := make([]Person, len(missing))
+	for j, i := range missing {
+		missingPersons[j] = persons[i]
+	}
+	fetched, err := fetch(ctx, missingPersons)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, i := range missing {
+		values[i] = fetched[j]
+		remember(ctx, p, cacheKey(upstream, persons[i].Name), fetched[j])
+	}
+	return values, nil
+}
+
+// answer is an upstream answer that can tell whether the name is known and
+// how confident the guess is.
+type answer interface {
+	known() bool
+	confident(minProbability float64) bool
+}
+
+func cacheKey(upstream, name string) string {
+	return "profiler:" + upstream + ":" + strings.ToLower(name)
+}
+
+func recall[T any](ctx context.Context, p *CachingProfiler, key string) (T, bool) {
+	var value T
+	data, err := p.cache.Get(ctx, key)
+	if err != nil {
+		return value, false
+	}
+	if err = json.Unmarshal(data, &value); err != nil {
+		return value, false
+	}
+	return value, true
+}
+
+// remember stores the answer. Answers about names the upstream doesn't know
+// are kept for negativeTTL.
+func remember[T answer](ctx context.Context, p *CachingProfiler, key string, value T) {
+	ttl := p.ttl
+	if !value.known() {
+		ttl = p.negativeTTL
+	}
+	if ttl <= 0 {
+		return
+	}
+	if data, err := json.Marshal(value); err == nil {
+		p.cache.Set(ctx, key, data, ttl) //nolint:errcheck
+	}
+}
+
+// unknownProfiler knows nothing about anyone.
+type unknownProfiler struct{}
+
+func (unknownProfiler) AgifyPerson(context.Context, Person) (AgifyResponse, error) {
+	return AgifyResponse{}, nil
+}
+
+func (unknownProfiler) GenderizePerson(context.Context, Person) (GenderizeResponse, error) {
+	return GenderizeResponse{}, nil
+}
+
+func (unknownProfiler) NationalizePerson(context.Context, Person) (NationalizeResponse, error) {
+	return NationalizeResponse{}, nil
+}
+
+func (p unknownProfiler) AgifyBatch(ctx context.Context, persons []Person) ([]AgifyResponse, error) {
+	return lookupBatch(ctx, persons, p.AgifyPerson)
+}
+
+func (p unknownProfiler) GenderizeBatch(ctx context.Context, persons []Person) ([]GenderizeResponse, error) {
+	return lookupBatch(ctx, persons, p.GenderizePerson)
+}
+
+func (p unknownProfiler) NationalizeBatch(ctx context.Context, persons []Person) ([]NationalizeResponse, error) {
+	return lookupBatch(ctx, persons, p.NationalizePerson)
+}
+
+func (p unknownProfiler) ProfileBatch(ctx context.Context, persons []Person) ([]Profile, error) {
+	return profileBatch(ctx, p, persons)
+}
@@ -0,0 +1,184 @@
+package profiler
+
+import (
+	"context"
+	"errors"
+	"fio/pkg/cache"
+	mock_cache "fio/pkg/cache/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	testTTL         = time.Hour
+	testNegativeTTL = time.Minute
+)
+
+func TestCachingProfiler_AgifyPerson(t *testing.T) {
+	type mockBehaviour func(c *mock_cache.MockCache)
+
+	tests := []struct {
+		name          string
+		ttl           time.Duration
+		negativeTTL   time.Duration
+		upstream      AgifyResponse
+		upstreamErr   error
+		mockBehaviour mockBehaviour
+		wantCalls     int
+		want          AgifyResponse
+		wantErr       error
+	}{
+		{
+			name:        "Hit",
+			ttl:         testTTL,
+			negativeTTL: testNegativeTTL,
+			mockBehaviour: func(c *mock_cache.MockCache) {
+				c.EXPECT().Get(gomock.Any(), "profiler:agify:dmitriy").Return([]byte(`{"age":42,"count":1200}`), nil)
+			},
+			want: AgifyResponse{Age: 42, Count: 1200},
+		},
+		{
+			name:        "Miss Remembered For TTL",
+			ttl:         testTTL,
+			negativeTTL: testNegativeTTL,
+			upstream:    AgifyResponse{Age: 42, Count: 1200},
+			mockBehaviour: func(c *mock_cache.MockCache) {
+				c.EXPECT().Get(gomock.Any(), "profiler:agify:dmitriy").Return(nil, cache.ErrItemNotFound)
+				c.EXPECT().Set(gomock.Any(), "profiler:agify:dmitriy", []byte(`{"age":42,"count":1200}`), testTTL).Return(nil)
+			},
+			wantCalls: 1,
+			want:      AgifyResponse{Age: 42, Count: 1200},
+		},
+		{
+			name:        "Unknown Name Remembered For Negative TTL",
+			ttl:         testTTL,
+			negativeTTL: testNegativeTTL,
+			mockBehaviour: func(c *mock_cache.MockCache) {
+				c.EXPECT().Get(gomock.Any(), "profiler:agify:dmitriy").Return(nil, cache.ErrItemNotFound)
+				c.EXPECT().Set(gomock.Any(), "profiler:agify:dmitriy", []byte(`{"age":0,"count":0}`), testNegativeTTL).Return(nil)
+			},
+			wantCalls: 1,
+		},
+		{
+			name:        "Zero TTL Not Remembered",
+			negativeTTL: testNegativeTTL,
+			upstream:    AgifyResponse{Age: 42, Count: 1200},
+			mockBehaviour: func(c *mock_cache.MockCache) {
+				c.EXPECT().Get(gomock.Any(), "profiler:agify:dmitriy").Return(nil, cache.ErrItemNotFound)
+			},
+			wantCalls: 1,
+			want:      AgifyResponse{Age: 42, Count: 1200},
+		},
+		{
+			name: "Zero Negative TTL Not Remembered",
+			ttl:  testTTL,
+			mockBehaviour: func(c *mock_cache.MockCache) {
+				c.EXPECT().Get(gomock.Any(), "profiler:agify:dmitriy").Return(nil, cache.ErrItemNotFound)
+			},
+			wantCalls: 1,
+		},
+		{
+			name:        "Corrupted Entry Refetched",
+			ttl:         testTTL,
+			negativeTTL: testNegativeTTL,
+			upstream:    AgifyResponse{Age: 42, Count: 1200},
+			mockBehaviour: func(c *mock_cache.MockCache) {
+				c.EXPECT().Get(gomock.Any(), "profiler:agify:dmitriy").Return([]byte(`{"age":`), nil)
+				c.EXPECT().Set(gomock.Any(), "profiler:agify:dmitriy", []byte(`{"age":42,"count":1200}`), testTTL).Return(nil)
+			},
+			wantCalls: 1,
+			want:      AgifyResponse{Age: 42, Count: 1200},
+		},
+		{
+			name:        "Upstream Error Not Remembered",
+			ttl:         testTTL,
+			negativeTTL: testNegativeTTL,
+			upstreamErr: ErrUnavailable,
+			mockBehaviour: func(c *mock_cache.MockCache) {
+				c.EXPECT().Get(gomock.Any(), "profiler:agify:dmitriy").Return(nil, cache.ErrItemNotFound)
+			},
+			wantCalls: 1,
+			wantErr:   ErrUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			cch := mock_cache.NewMockCache(c)
+			tt.mockBehaviour(cch)
+
+			var calls int
+			upstream := stubProfiler{agify: func(context.Context, Person) (AgifyResponse, error) {
+				calls++
+				return tt.upstream, tt.upstreamErr
+			}}
+			p := NewCachingProfiler(upstream, cch, tt.ttl, tt.negativeTTL)
+
+			got, err := p.AgifyPerson(context.Background(), Person{Name: "Dmitriy"})
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
+
+// batchProfiler records the persons its batch age lookups are asked about.
+type batchProfiler struct {
+	unknownProfiler
+	ages  map[string]int
+	asked *[][]Person
+}
+
+func (p batchProfiler) AgifyBatch(_ context.Context, persons []Person) ([]AgifyResponse, error) {
+	*p.asked = append(*p.asked, persons)
+	resp := make([]AgifyResponse, len(persons))
+	for i, person := range persons {
+		resp[i] = AgifyResponse{Age: p.ages[person.Name], Count: 1}
+	}
+	return resp, nil
+}
+
+func TestCachingProfiler_AgifyBatch(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	cch := mock_cache.NewMockCache(c)
+	cch.EXPECT().Get(gomock.Any(), "profiler:agify:dmitriy").Return([]byte(`{"age":42,"count":1200}`), nil)
+	cch.EXPECT().Get(gomock.Any(), "profiler:agify:anna").Return(nil, cache.ErrItemNotFound)
+	cch.EXPECT().Get(gomock.Any(), "profiler:agify:ivan").Return(nil, cache.ErrItemNotFound)
+	cch.EXPECT().Set(gomock.Any(), "profiler:agify:anna", []byte(`{"age":30,"count":1}`), testTTL).Return(nil)
+	// a failed cache write only costs a lookup next time
+	cch.EXPECT().Set(gomock.Any(), "profiler:agify:ivan", []byte(`{"age":55,"count":1}`), testTTL).
+		Return(errors.New("cache is down"))
+
+	var asked [][]Person
+	upstream := batchProfiler{ages: map[string]int{"Anna": 30, "Ivan": 55}, asked: &asked}
+	p := NewCachingProfiler(upstream, cch, testTTL, testNegativeTTL)
+
+	got, err := p.AgifyBatch(context.Background(), []Person{{Name: "Anna"}, {Name: "Dmitriy"}, {Name: "Ivan"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []AgifyResponse{{Age: 30, Count: 1}, {Age: 42, Count: 1200}, {Age: 55, Count: 1}}, got)
+	assert.Equal(t, [][]Person{{{Name: "Anna"}, {Name: "Ivan"}}}, asked)
+}
+
+func TestCachingProfiler_AgifyBatch_AllCached(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	cch := mock_cache.NewMockCache(c)
+	cch.EXPECT().Get(gomock.Any(), "profiler:agify:dmitriy").Return([]byte(`{"age":42,"count":1200}`), nil).Times(2)
+
+	var asked [][]Person
+	p := NewCachingProfiler(batchProfiler{asked: &asked}, cch, testTTL, testNegativeTTL)
+
+	got, err := p.AgifyBatch(context.Background(), []Person{{Name: "Dmitriy"}, {Name: "DMITRIY"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []AgifyResponse{{Age: 42, Count: 1200}, {Age: 42, Count: 1200}}, got)
+	assert.Empty(t, asked)
+}
@@ -0,0 +1,204 @@
+package profiler
+
+import "context"
+
+// Provider is a named profiler of a chain. The name is recorded as the
+// source of the answers it gives.
+type Provider struct {
+	Name     string
+	Profiler Profiler
+}
+
+// ChainSettings lists the providers asked for every attribute, in order.
+type ChainSettings struct {
+	Age         []Provider
+	Gender      []Provider
+	Nationality []Provider
+	// MinProbability is the least probability of a confident answer.
+	MinProbability float64
+}
+
+// ChainProfiler asks the providers of an attribute in order and takes the
+// first confident answer. If no answer is confident the first one that knows
+// the person is taken. A failed provider is skipped, its error is returned
+// only if no later provider knows the answer either.
+type ChainProfiler struct {
+	settings ChainSettings
+}
+
+func NewChainProfiler(settings ChainSettings) *ChainProfiler {
+	return &ChainProfiler{settings: settings}
+}
+
+func (p *ChainProfiler) AgifyPerson(ctx context.Context, person Person) (AgifyResponse, error) {
+	lookups := make([]func(context.Context, Person) (AgifyResponse, error), len(p.settings.Age))
+	for i, provider := range p.settings.Age {
+		lookups[i] = provider.Profiler.AgifyPerson
+	}
+	answer, from, err := chainPerson(ctx, person, lookups, p.settings.MinProbability)
+	if from >= 0 {
+		answer.Source = p.settings.Age[from].Name
+	}
+	return answer, err
+}
+
+func (p *ChainProfiler) GenderizePerson(ctx context.Context, person Person) (GenderizeResponse, error) {
+	lookups := make([]func(context.Context, Person) (GenderizeResponse, error), len(p.settings.Gender))
+	for i, provider := range p.settings.Gender {
+		lookups[i] = provider.Profiler.GenderizePerson
+	}
+	answer, from, err := chainPerson(ctx, person, lookups, p.settings.MinProbability)
+	if from >= 0 {
+		answer.Source = p.settings.Gender[from].Name
+	}
+	return answer, err
+}
+
+func (p *ChainProfiler) NationalizePerson(ctx context.Context, person Person) (NationalizeResponse, error) {
+	lookups := make([]func(context.Context, Person) (NationalizeResponse, error), len(p.settings.Nationality))
+	for i, provider := range p.settings.Nationality {
+		lookups[i] = provider.Profiler.NationalizePerson
+	}
+	answer, from, err := chainPerson(ctx, person, lookups, p.settings.MinProbability)
+	if from >= 0 {
+		answer.Source = p.settings.Nationality[from].Name
+	}
+	return answer, err
+}
+
+func (p *ChainProfiler) AgifyBatch(ctx context.Context, persons []Person) ([]AgifyResponse, error) {
+	lookups := make([]func(context.Context, []Person) ([]AgifyResponse, error), len(p.settings.Age))
+	for i, provider := range p.settings.Age {
+		lookups[i] = provider.Profiler.AgifyBatch
+	}
+	answers, from, err := chainBatch(ctx, persons, lookups, p.settings.MinProbability)
+	for i := range answers {
+		if from[i] >= 0 {
+			answers[i].Source = p.settings.Age[from[i]].Name
+		}
+	}
+	return answers, err
+}
+
+func (p *ChainProfiler) GenderizeBatch(ctx context.Context, persons []Person) ([]GenderizeResponse, error) {
+	lookups := make([]func(context.Context, []Person) ([]GenderizeResponse, error), len(p.settings.Gender))
+	for i, provider := range p.settings.Gender {
+		lookups[i] = provider.Profiler.GenderizeBatch
+	}
+	answers, from, err := chainBatch(ctx, persons, lookups, p.settings.MinProbability)
+	for i := range answers {
+		if from[i] >= 0 {
+			answers[i].Source = p.settings.Gender[from[i]].Name
+		}
+	}
+	return answers, err
+}
+
+func (p *ChainProfiler) NationalizeBatch(ctx context.Context, persons []Person) ([]NationalizeResponse, error) {
+	lookups := make([]func(context.Context, []Person) ([]NationalizeResponse, error), len(p.settings.Nationality))
+	for i, provider := range p.settings.Nationality {
+		lookups[i] = provider.Profiler.NationalizeBatch
+	}
+	answers, from, err := chainBatch(ctx, persons, lookups, p.settings.MinProbability)
+	for i := range answers {
+		if from[i] >= 0 {
+			answers[i].Source = p.settings.Nationality[from[i]].Name
+		}
+	}
+	return answers, err
+}
+
+func (p *ChainProfiler) ProfileBatch(ctx context.Context, persons []Person) ([]Profile, error) {
+	return profileBatch(ctx, p, persons)
+}
+
+// chainPerson returns the chosen answer and the index of the lookup that gave
+// it, -1 if no lookup knows the person.
+func chainPerson[T answer](ctx context.Context, person Person,
+	lookups []func(context.Context, Person) (T, error), minProbability float64) (T, int, error) {
+	var (
+		chosen   T
+		from     = -1
+		firstErr error
+	)
+	for i, lookup := range lookups {
+		answer, err := lookup(ctx, person)
+		if err != nil {
+			if ctx.Err() != nil {
+				return chosen, -1, ctx.Err()
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if answer.confident(minProbability) {
+			return answer, i, nil
+		}
+		if from < 0 && answer.known() {
+			chosen, from = answer, i
+		}
+	}
+	if from < 0 {
+		return chosen, from, firstErr
+	}
+	return chosen, from, nil
+}
+
+// chainBatch is chainPerson for batch lookups, every lookup is passed only
+// the persons no earlier lookup was confident about.
+func chainBatch[T answer](ctx context.Context, persons []Person,
+	lookups []func(context.Context, []Person) ([]T, error), minProbability float64) ([]T, []int, error) {
+	chosen := make([]T, len(persons))
+	from := make([]int, len(persons))
+	pending := make([]int, len(persons))
+	for i := range persons {
+		from[i] = -1
+		pending[i] = i
+	}
+
+	var firstErr error
+	for l, lookup := range lookups {
+		if len(pending) == 0 {
+			break
+		}
+
+		batch := make([]Person, len(pending))
+		for j, i := range pending {
+			batch[j] = persons[i]
+		}
+		answers, err := lookup(ctx, batch)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, nil, ctx.Err()
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		unsure := pending[:0]
+		for j, i := range pending {
+			answer := answers[j]
+			switch {
+			case answer.confident(minProbability):
+				chosen[i], from[i] = answer, l
+				continue
+			case from[i] < 0 && answer.known():
+				chosen[i], from[i] = answer, l
+			}
+			unsure = append(unsure, i)
+		}
+		pending = unsure
+	}
+
+	if firstErr != nil {
+		for _, i := range pending {
+			if from[i] < 0 {
+				return nil, nil, firstErr
+			}
+		}
+	}
+	return chosen, from, nil
+}
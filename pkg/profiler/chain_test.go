@@ -0,0 +1,236 @@
+package profiler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// genderProfiler answers gender lookups from its map, or fails with err. It
+// records the persons its batch lookups are asked about.
+type genderProfiler struct {
+	unknownProfiler
+	genders map[string]GenderizeResponse
+	err     error
+	asked   *[][]Person
+}
+
+func (p genderProfiler) GenderizePerson(_ context.Context, person Person) (GenderizeResponse, error) {
+	if p.asked != nil {
+		*p.asked = append(*p.asked, []Person{person})
+	}
+	if p.err != nil {
+		return GenderizeResponse{}, p.err
+	}
+	return p.genders[strings.ToLower(person.Name)], nil
+}
+
+func (p genderProfiler) GenderizeBatch(ctx context.Context, persons []Person) ([]GenderizeResponse, error) {
+	if p.asked != nil {
+		*p.asked = append(*p.asked, persons)
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	resp := make([]GenderizeResponse, len(persons))
+	for i, person := range persons {
+		resp[i] = p.genders[strings.ToLower(person.Name)]
+	}
+	return resp, nil
+}
+
+var errProviderDown = errors.New("provider is down")
+
+func TestChainProfiler_GenderizePerson(t *testing.T) {
+	female := func(probability float64) GenderizeResponse {
+		return GenderizeResponse{Gender: "female", Probability: probability}
+	}
+
+	tests := []struct {
+		name      string
+		providers []genderProfiler
+		want      GenderizeResponse
+		wantAsked []int
+		wantErr   error
+	}{
+		{
+			name: "First Confident Wins",
+			providers: []genderProfiler{
+				{genders: map[string]GenderizeResponse{"anna": female(0.95)}},
+				{genders: map[string]GenderizeResponse{"anna": {Gender: "male", Probability: 0.99}}},
+			},
+			want:      GenderizeResponse{Gender: "female", Probability: 0.95, Source: "p0"},
+			wantAsked: []int{1, 0},
+		},
+		{
+			name: "Later Confident Beats Earlier Unsure",
+			providers: []genderProfiler{
+				{genders: map[string]GenderizeResponse{"anna": {Gender: "male", Probability: 0.6}}},
+				{genders: map[string]GenderizeResponse{"anna": female(0.95)}},
+			},
+			want:      GenderizeResponse{Gender: "female", Probability: 0.95, Source: "p1"},
+			wantAsked: []int{1, 1},
+		},
+		{
+			name: "Falls Back To First Known",
+			providers: []genderProfiler{
+				{},
+				{genders: map[string]GenderizeResponse{"anna": female(0.6)}},
+				{genders: map[string]GenderizeResponse{"anna": {Gender: "male", Probability: 0.7}}},
+			},
+			want:      GenderizeResponse{Gender: "female", Probability: 0.6, Source: "p1"},
+			wantAsked: []int{1, 1, 1},
+		},
+		{
+			name: "Failing Provider Skipped",
+			providers: []genderProfiler{
+				{err: errProviderDown},
+				{genders: map[string]GenderizeResponse{"anna": female(0.6)}},
+			},
+			want:      GenderizeResponse{Gender: "female", Probability: 0.6, Source: "p1"},
+			wantAsked: []int{1, 1},
+		},
+		{
+			name: "Error Surfaced If Nobody Knows",
+			providers: []genderProfiler{
+				{err: errProviderDown},
+				{},
+			},
+			wantAsked: []int{1, 1},
+			wantErr:   errProviderDown,
+		},
+		{
+			name:      "Nobody Knows",
+			providers: []genderProfiler{{}, {}},
+			wantAsked: []int{1, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asked := make([][][]Person, len(tt.providers))
+			var settings ChainSettings
+			for i, provider := range tt.providers {
+				provider.asked = &asked[i]
+				settings.Gender = append(settings.Gender, Provider{Name: fmt.Sprintf("p%d", i), Profiler: provider})
+			}
+			settings.MinProbability = 0.9
+
+			got, err := NewChainProfiler(settings).GenderizePerson(context.Background(), Person{Name: "Anna"})
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+			for i, want := range tt.wantAsked {
+				assert.Len(t, asked[i], want, "provider %d", i)
+			}
+		})
+	}
+}
+
+func TestChainProfiler_GenderizePerson_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var asked [][]Person
+	p := NewChainProfiler(ChainSettings{Gender: []Provider{
+		{Name: "down", Profiler: genderProfiler{err: context.Canceled}},
+		{Name: "later", Profiler: genderProfiler{asked: &asked}},
+	}})
+
+	_, err := p.GenderizePerson(ctx, Person{Name: "Anna"})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, asked)
+}
+
+func TestChainProfiler_GenderizeBatch(t *testing.T) {
+	persons := []Person{{Name: "Anna"}, {Name: "Ivan"}, {Name: "Sasha"}, {Name: "Kim"}}
+
+	var firstAsked, secondAsked [][]Person
+	p := NewChainProfiler(ChainSettings{
+		Gender: []Provider{
+			{Name: "dictionary", Profiler: genderProfiler{asked: &firstAsked, genders: map[string]GenderizeResponse{
+				"anna": {Gender: "female", Probability: 0.95},
+				"ivan": {Gender: "male", Probability: 0.6},
+			}}},
+			{Name: "morphology", Profiler: genderProfiler{asked: &secondAsked, genders: map[string]GenderizeResponse{
+				"ivan":  {Gender: "female", Probability: 0.7},
+				"sasha": {Gender: "female", Probability: 0.99},
+			}}},
+		},
+		MinProbability: 0.9,
+	})
+
+	got, err := p.GenderizeBatch(context.Background(), persons)
+	assert.NoError(t, err)
+	assert.Equal(t, []GenderizeResponse{
+		{Gender: "female", Probability: 0.95, Source: "dictionary"},
+		{Gender: "male", Probability: 0.6, Source: "dictionary"},
+		{Gender: "female", Probability: 0.99, Source: "morphology"},
+		{},
+	}, got)
+	assert.Equal(t, [][]Person{persons}, firstAsked)
+	// the later provider is asked only about the persons still pending
+	assert.Equal(t, [][]Person{{{Name: "Ivan"}, {Name: "Sasha"}, {Name: "Kim"}}}, secondAsked)
+}
+
+func TestChainProfiler_GenderizeBatch_FailingProvider(t *testing.T) {
+	persons := []Person{{Name: "Anna"}, {Name: "Ivan"}}
+	down := Provider{Name: "remote", Profiler: genderProfiler{err: errProviderDown}}
+
+	tests := []struct {
+		name    string
+		genders map[string]GenderizeResponse
+		want    []GenderizeResponse
+		wantErr error
+	}{
+		{
+			name: "Skipped When Later Provider Knows Everyone",
+			genders: map[string]GenderizeResponse{
+				"anna": {Gender: "female", Probability: 0.5},
+				"ivan": {Gender: "male", Probability: 0.99},
+			},
+			want: []GenderizeResponse{
+				{Gender: "female", Probability: 0.5, Source: "dictionary"},
+				{Gender: "male", Probability: 0.99, Source: "dictionary"},
+			},
+		},
+		{
+			name: "Surfaced When Someone Stays Unknown",
+			genders: map[string]GenderizeResponse{
+				"ivan": {Gender: "male", Probability: 0.99},
+			},
+			wantErr: errProviderDown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewChainProfiler(ChainSettings{
+				Gender:         []Provider{down, {Name: "dictionary", Profiler: genderProfiler{genders: tt.genders}}},
+				MinProbability: 0.9,
+			})
+
+			got, err := p.GenderizeBatch(context.Background(), persons)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestChainProfiler_ProfileBatch_SourcePerAttribute(t *testing.T) {
+	p := NewChainProfiler(ChainSettings{
+		Age:         []Provider{{Name: "dictionary", Profiler: &lookupProfiler{ages: map[string]int{"ivan": 42}}}},
+		Gender:      []Provider{{Name: "morphology", Profiler: NewMorphologyProfiler()}},
+		Nationality: []Provider{{Name: "remote", Profiler: unknownProfiler{}}},
+	})
+
+	got, err := p.ProfileBatch(context.Background(), []Person{{Name: "Ivan", Surname: "Ivanov"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "dictionary", got[0].Age.Source)
+	assert.Equal(t, "morphology", got[0].Gender.Source)
+	// unknown answers have no source
+	assert.Equal(t, NationalizeResponse{}, got[0].Nationality)
+}
@@ -0,0 +1,172 @@
+package profiler
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var ErrUnknownFormat = errors.New("profiler: unknown dictionary format")
+
+// DictionaryProfiler answers lookups from a local name statistics dataset,
+// so no upstream has to be reachable. Names missing from the dataset are
+// answered like an upstream answers names it doesn't know.
+type DictionaryProfiler struct {
+	entries map[string]dictionaryEntry
+}
+
+// dictionaryEntry is a record of the dataset. In JSON the dataset is a list of
+// records. In CSV it has the header
+//
+//	name,age,gender,probability,count,country
+//
+// where country lists the countries with their probabilities, the most likely
+// first, e.g. "RU:0.81;UA:0.12".
+type dictionaryEntry struct {
+	Name        string    `json:"name"`
+	Age         int       `json:"age"`
+	Gender      string    `json:"gender"`
+	Probability float64   `json:"probability"`
+	Count       int       `json:"count"`
+	Country     []Country `json:"country"`
+}
+
+// NewDictionaryProfiler loads the dataset at path, the format is picked by
+// the .csv or .json extension.
+func NewDictionaryProfiler(path string) (*DictionaryProfiler, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []dictionaryEntry
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.NewDecoder(file).Decode(&entries)
+	case ".csv":
+		entries, err = readDictionaryCSV(file)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load dictionary %s: %w", path, err)
+	}
+
+	p := &DictionaryProfiler{entries: make(map[string]dictionaryEntry, len(entries))}
+	for _, entry := range entries {
+		p.entries[strings.ToLower(entry.Name)] = entry
+	}
+	return p, nil
+}
+
+func readDictionaryCSV(r io.Reader) ([]dictionaryEntry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 6 //nolint:gomnd
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	entries := make([]dictionaryEntry, 0, len(records)-1)
+	for i, record := range records[1:] {
+		entry, err := parseDictionaryRecord(record)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+2, err) //nolint:gomnd
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func parseDictionaryRecord(record []string) (dictionaryEntry, error) {
+	entry := dictionaryEntry{Name: record[0], Gender: record[2]}
+
+	var err error
+	if record[1] != "" {
+		if entry.Age, err = strconv.Atoi(record[1]); err != nil {
+			return entry, fmt.Errorf("bad age: %w", err)
+		}
+	}
+	if record[3] != "" {
+		if entry.Probability, err = strconv.ParseFloat(record[3], 64); err != nil {
+			return entry, fmt.Errorf("bad probability: %w", err)
+		}
+	}
+	if record[4] != "" {
+		if entry.Count, err = strconv.Atoi(record[4]); err != nil {
+			return entry, fmt.Errorf("bad count: %w", err)
+		}
+	}
+
+	for _, country := range strings.Split(record[5], ";") {
+		if country == "" {
+			continue
+		}
+		id, probability, _ := strings.Cut(country, ":")
+		c := Country{CountryID: id}
+		if probability != "" {
+			if c.Probability, err = strconv.ParseFloat(probability, 64); err != nil {
+				return entry, fmt.Errorf("bad country probability: %w", err)
+			}
+		}
+		entry.Country = append(entry.Country, c)
+	}
+	return entry, nil
+}
+
+func (p *DictionaryProfiler) AgifyPerson(_ context.Context, person Person) (AgifyResponse, error) {
+	entry := p.entries[strings.ToLower(person.Name)]
+	return AgifyResponse{Age: entry.Age, Count: entry.Count}, nil
+}
+
+func (p *DictionaryProfiler) GenderizePerson(_ context.Context, person Person) (GenderizeResponse, error) {
+	entry := p.entries[strings.ToLower(person.Name)]
+	return GenderizeResponse{Gender: entry.Gender, Probability: entry.Probability, Count: entry.Count}, nil
+}
+
+func (p *DictionaryProfiler) NationalizePerson(_ context.Context, person Person) (NationalizeResponse, error) {
+	entry := p.entries[strings.ToLower(person.Name)]
+	return NationalizeResponse{Country: entry.Country, Count: entry.Count}, nil
+}
+
+func (p *DictionaryProfiler) AgifyBatch(ctx context.Context, persons []Person) ([]AgifyResponse, error) {
+	return lookupBatch(ctx, persons, p.AgifyPerson)
+}
+
+func (p *DictionaryProfiler) GenderizeBatch(ctx context.Context, persons []Person) ([]GenderizeResponse, error) {
+	return lookupBatch(ctx, persons, p.GenderizePerson)
+}
+
+func (p *DictionaryProfiler) NationalizeBatch(ctx context.Context, persons []Person) ([]NationalizeResponse, error) {
+	return lookupBatch(ctx, persons, p.NationalizePerson)
+}
+
+func (p *DictionaryProfiler) ProfileBatch(ctx context.Context, persons []Person) ([]Profile, error) {
+	return profileBatch(ctx, p, persons)
+}
+
+// lookupBatch answers a batch lookup with a lookup per person.
+func lookupBatch[T any](ctx context.Context, persons []Person,
+	lookup func(context.Context, Person) (T, error)) ([]T, error) {
+	values := make([]T, len(persons))
+	for i, person := range persons {
+		value, err := lookup(ctx, person)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
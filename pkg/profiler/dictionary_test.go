@@ -0,0 +1,139 @@
+package profiler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeDictionary(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestNewDictionaryProfiler(t *testing.T) {
+	dmitriy := Profile{
+		Age:    AgifyResponse{Age: 45, Count: 16749},
+		Gender: GenderizeResponse{Gender: "male", Probability: 1, Count: 16749},
+		Nationality: NationalizeResponse{Country: []Country{
+			{CountryID: "RU", Probability: 0.71},
+			{CountryID: "UA", Probability: 0.11},
+		}, Count: 16749},
+	}
+
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		lookup   string
+		want     Profile
+		wantErr  error
+		errorMsg string
+	}{
+		{
+			name:    "CSV",
+			file:    "names.csv",
+			content: "name,age,gender,probability,count,country\nDmitriy,45,male,1,16749,RU:0.71;UA:0.11\n",
+			lookup:  "Dmitriy",
+			want:    dmitriy,
+		},
+		{
+			name: "JSON",
+			file: "names.JSON",
+			content: `[{"name":"Dmitriy","age":45,"gender":"male","probability":1,"count":16749,` +
+				`"country":[{"country_id":"RU","probability":0.71},{"country_id":"UA","probability":0.11}]}]`,
+			lookup: "Dmitriy",
+			want:   dmitriy,
+		},
+		{
+			name:    "Case Insensitive Lookup",
+			file:    "names.csv",
+			content: "name,age,gender,probability,count,country\nDMITRIY,45,male,1,16749,RU:0.71;UA:0.11\n",
+			lookup:  "dmitriY",
+			want:    dmitriy,
+		},
+		{
+			name:    "Empty Fields",
+			file:    "names.csv",
+			content: "name,age,gender,probability,count,country\nAlex,,,,,\n",
+			lookup:  "Alex",
+			want:    Profile{},
+		},
+		{
+			name:    "Unknown Name",
+			file:    "names.csv",
+			content: "name,age,gender,probability,count,country\nDmitriy,45,male,1,16749,RU:0.71;UA:0.11\n",
+			lookup:  "Anna",
+			want:    Profile{},
+		},
+		{
+			name:     "Bad Age",
+			file:     "names.csv",
+			content:  "name,age,gender,probability,count,country\nDmitriy,45,male,1,16749,RU\nAnna,old,female,1,1,RU\n",
+			errorMsg: "line 3: bad age",
+		},
+		{
+			name:     "Bad Country Probability",
+			file:     "names.csv",
+			content:  "name,age,gender,probability,count,country\nDmitriy,45,male,1,16749,RU:high\n",
+			errorMsg: "line 2: bad country probability",
+		},
+		{
+			name:     "Wrong Number Of Fields",
+			file:     "names.csv",
+			content:  "name,age,gender,probability,count,country\nDmitriy,45,male\n",
+			errorMsg: "line 2",
+		},
+		{
+			name:     "Malformed JSON",
+			file:     "names.json",
+			content:  `[{"name":"Dmitriy","age":"45"}]`,
+			errorMsg: "failed to load dictionary",
+		},
+		{
+			name:    "Unknown Extension",
+			file:    "names.txt",
+			content: "Dmitriy",
+			wantErr: ErrUnknownFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewDictionaryProfiler(writeDictionary(t, tt.file, tt.content))
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			if tt.errorMsg != "" {
+				assert.ErrorContains(t, err, tt.errorMsg)
+				return
+			}
+			assert.NoError(t, err)
+
+			got, err := p.ProfileBatch(context.Background(), []Person{{Name: tt.lookup}})
+			assert.NoError(t, err)
+			assert.Equal(t, []Profile{tt.want}, got)
+		})
+	}
+}
+
+func TestNewDictionaryProfiler_MissingFile(t *testing.T) {
+	_, err := NewDictionaryProfiler(filepath.Join(t.TempDir(), "names.csv"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestNewDictionaryProfiler_ShippedDictionary(t *testing.T) {
+	p, err := NewDictionaryProfiler(filepath.Join("..", "..", "configs", "names.csv"))
+	assert.NoError(t, err)
+
+	got, err := p.GenderizePerson(context.Background(), Person{Name: "dmitriy"})
+	assert.NoError(t, err)
+	assert.Equal(t, "male", got.Gender)
+}
@@ -5,6 +5,8 @@
 package mock_profiler
 
 import (
+	context "context"
+	profiler "fio/pkg/profiler"
 	reflect "reflect"
 
 	gomock "go.uber.org/mock/gomock"
@@ -33,47 +35,107 @@ func (m *MockProfiler) EXPECT() *MockProfilerMockRecorder {
 	return m.recorder
 }
 
+// AgifyBatch mocks base method.
+func (m *MockProfiler) AgifyBatch(ctx context.Context, persons []profiler.Person) ([]profiler.AgifyResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AgifyBatch", ctx, persons)
+	ret0, _ := ret[0].([]profiler.AgifyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AgifyBatch indicates an expected call of AgifyBatch.
+func (mr *MockProfilerMockRecorder) AgifyBatch(ctx, persons interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AgifyBatch", reflect.TypeOf((*MockProfiler)(nil).AgifyBatch), ctx, persons)
+}
+
 // AgifyPerson mocks base method.
-func (m *MockProfiler) AgifyPerson(name string) (int, error) {
+func (m *MockProfiler) AgifyPerson(ctx context.Context, person profiler.Person) (profiler.AgifyResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AgifyPerson", name)
-	ret0, _ := ret[0].(int)
+	ret := m.ctrl.Call(m, "AgifyPerson", ctx, person)
+	ret0, _ := ret[0].(profiler.AgifyResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AgifyPerson indicates an expected call of AgifyPerson.
-func (mr *MockProfilerMockRecorder) AgifyPerson(name interface{}) *gomock.Call {
+func (mr *MockProfilerMockRecorder) AgifyPerson(ctx, person interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AgifyPerson", reflect.TypeOf((*MockProfiler)(nil).AgifyPerson), ctx, person)
+}
+
+// GenderizeBatch mocks base method.
+func (m *MockProfiler) GenderizeBatch(ctx context.Context, persons []profiler.Person) ([]profiler.GenderizeResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenderizeBatch", ctx, persons)
+	ret0, _ := ret[0].([]profiler.GenderizeResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenderizeBatch indicates an expected call of GenderizeBatch.
+func (mr *MockProfilerMockRecorder) GenderizeBatch(ctx, persons interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AgifyPerson", reflect.TypeOf((*MockProfiler)(nil).AgifyPerson), name)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenderizeBatch", reflect.TypeOf((*MockProfiler)(nil).GenderizeBatch), ctx, persons)
 }
 
 // GenderizePerson mocks base method.
-func (m *MockProfiler) GenderizePerson(name string) (string, error) {
+func (m *MockProfiler) GenderizePerson(ctx context.Context, person profiler.Person) (profiler.GenderizeResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GenderizePerson", name)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "GenderizePerson", ctx, person)
+	ret0, _ := ret[0].(profiler.GenderizeResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GenderizePerson indicates an expected call of GenderizePerson.
-func (mr *MockProfilerMockRecorder) GenderizePerson(name interface{}) *gomock.Call {
+func (mr *MockProfilerMockRecorder) GenderizePerson(ctx, person interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenderizePerson", reflect.TypeOf((*MockProfiler)(nil).GenderizePerson), name)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenderizePerson", reflect.TypeOf((*MockProfiler)(nil).GenderizePerson), ctx, person)
+}
+
+// NationalizeBatch mocks base method.
+func (m *MockProfiler) NationalizeBatch(ctx context.Context, persons []profiler.Person) ([]profiler.NationalizeResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NationalizeBatch", ctx, persons)
+	ret0, _ := ret[0].([]profiler.NationalizeResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NationalizeBatch indicates an expected call of NationalizeBatch.
+func (mr *MockProfilerMockRecorder) NationalizeBatch(ctx, persons interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NationalizeBatch", reflect.TypeOf((*MockProfiler)(nil).NationalizeBatch), ctx, persons)
 }
 
 // NationalizePerson mocks base method.
-func (m *MockProfiler) NationalizePerson(name string) (string, error) {
+func (m *MockProfiler) NationalizePerson(ctx context.Context, person profiler.Person) (profiler.NationalizeResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "NationalizePerson", name)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "NationalizePerson", ctx, person)
+	ret0, _ := ret[0].(profiler.NationalizeResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // NationalizePerson indicates an expected call of NationalizePerson.
-func (mr *MockProfilerMockRecorder) NationalizePerson(name interface{}) *gomock.Call {
+func (mr *MockProfilerMockRecorder) NationalizePerson(ctx, person interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NationalizePerson", reflect.TypeOf((*MockProfiler)(nil).NationalizePerson), ctx, person)
+}
+
+// ProfileBatch mocks base method.
+func (m *MockProfiler) ProfileBatch(ctx context.Context, persons []profiler.Person) ([]profiler.Profile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProfileBatch", ctx, persons)
+	ret0, _ := ret[0].([]profiler.Profile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProfileBatch indicates an expected call of ProfileBatch.
+func (mr *MockProfilerMockRecorder) ProfileBatch(ctx, persons interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NationalizePerson", reflect.TypeOf((*MockProfiler)(nil).NationalizePerson), name)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProfileBatch", reflect.TypeOf((*MockProfiler)(nil).ProfileBatch), ctx, persons)
 }
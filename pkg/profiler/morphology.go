@@ -0,0 +1,130 @@
+package profiler
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// The probabilities of the rules are rough priors rather than statistics,
+// a patronymic determines the gender almost always, a surname nearly so.
+const (
+	patronymicGenderProbability = 0.99
+	surnameGenderProbability    = 0.9
+)
+
+type suffixRule struct {
+	suffix string
+	value  string
+}
+
+var patronymicGenders = sortRules([]suffixRule{
+	{"ovich", "male"}, {"evich", "male"}, {"ich", "male"},
+	{"ovna", "female"}, {"evna", "female"}, {"ichna", "female"},
+	{"ович", "male"}, {"евич", "male"}, {"ич", "male"},
+	{"овна", "female"}, {"евна", "female"}, {"ична", "female"},
+})
+
+// surnameGenders match the transliterated surnames as well. Many Western
+// surnames share the Latin in and ina endings, the common ones are listed as
+// exceptions without a gender.
+var surnameGenders = sortRules([]suffixRule{
+	{"ov", "male"}, {"ev", "male"}, {"in", "male"}, {"yn", "male"},
+	{"sky", "male"}, {"skiy", "male"}, {"skii", "male"}, {"skoy", "male"},
+	{"ova", "female"}, {"eva", "female"}, {"ina", "female"}, {"yna", "female"}, {"skaya", "female"},
+	{"ain", ""}, {"ein", ""}, {"win", ""}, {"lyn", ""}, {"rtin", ""}, {"klin", ""}, {"plin", ""},
+	{"ssina", ""}, {"edina", ""},
+	{"ов", "male"}, {"ев", "male"}, {"ёв", "male"}, {"ин", "male"}, {"ын", "male"},
+	{"ский", "male"}, {"цкий", "male"}, {"ской", "male"},
+	{"ова", "female"}, {"ева", "female"}, {"ёва", "female"}, {"ина", "female"}, {"ына", "female"},
+	{"ская", "female"}, {"цкая", "female"},
+})
+
+// surnameNationalities are surname endings typical of a country. Anyone else
+// with a Slavic patronymic is taken for Russian.
+var surnameNationalities = sortRules([]suffixRule{
+	{"ov", "RU"}, {"ev", "RU"}, {"ova", "RU"}, {"eva", "RU"}, {"skiy", "RU"}, {"skaya", "RU"},
+	{"ов", "RU"}, {"ев", "RU"}, {"ова", "RU"}, {"ева", "RU"}, {"ский", "RU"}, {"ская", "RU"},
+	{"enko", "UA"}, {"chuk", "UA"}, {"yuk", "UA"},
+	{"енко", "UA"}, {"чук", "UA"}, {"юк", "UA"},
+	{"enka", "BY"}, {"onak", "BY"}, {"енка", "BY"}, {"онак", "BY"},
+})
+
+var slavicCountries = map[string][]Country{
+	"RU": {{"RU", 0.7}, {"UA", 0.15}, {"BY", 0.1}},
+	"UA": {{"UA", 0.8}, {"RU", 0.15}},
+	"BY": {{"BY", 0.8}, {"RU", 0.15}},
+}
+
+// sortRules puts longer suffixes first, so the most specific rule matches.
+func sortRules(rules []suffixRule) []suffixRule {
+	sort.SliceStable(rules, func(i, j int) bool {
+		return len(rules[i].suffix) > len(rules[j].suffix)
+	})
+	return rules
+}
+
+// matchSuffix returns the value of the longest suffix of word that has a rule.
+// A rule without a value is an exception, the word is left unknown.
+func matchSuffix(rules []suffixRule, word string) string {
+	word = strings.ToLower(strings.TrimSpace(word))
+	for _, rule := range rules {
+		if len(word) > len(rule.suffix) && strings.HasSuffix(word, rule.suffix) {
+			return rule.value
+		}
+	}
+	return ""
+}
+
+// MorphologyProfiler infers the gender from the endings of Russian and other
+// Slavic patronymics and surnames, and a likely nationality from the same
+// endings. It knows nothing about age and never fails, persons without such
+// endings are answered as unknown.
+type MorphologyProfiler struct{}
+
+func NewMorphologyProfiler() *MorphologyProfiler {
+	return &MorphologyProfiler{}
+}
+
+func (p *MorphologyProfiler) AgifyPerson(_ context.Context, _ Person) (AgifyResponse, error) {
+	return AgifyResponse{}, nil
+}
+
+func (p *MorphologyProfiler) GenderizePerson(_ context.Context, person Person) (GenderizeResponse, error) {
+	if gender := matchSuffix(patronymicGenders, person.Patronymic); gender != "" {
+		return GenderizeResponse{Gender: gender, Probability: patronymicGenderProbability}, nil
+	}
+	if gender := matchSuffix(surnameGenders, person.Surname); gender != "" {
+		return GenderizeResponse{Gender: gender, Probability: surnameGenderProbability}, nil
+	}
+	return GenderizeResponse{}, nil
+}
+
+func (p *MorphologyProfiler) NationalizePerson(_ context.Context, person Person) (NationalizeResponse, error) {
+	nationality := matchSuffix(surnameNationalities, person.Surname)
+	if nationality == "" && matchSuffix(patronymicGenders, person.Patronymic) != "" {
+		nationality = "RU"
+	}
+
+	countries := slavicCountries[nationality]
+	if len(countries) == 0 {
+		return NationalizeResponse{}, nil
+	}
+	return NationalizeResponse{Country: append([]Country(nil), countries...)}, nil
+}
+
+func (p *MorphologyProfiler) AgifyBatch(ctx context.Context, persons []Person) ([]AgifyResponse, error) {
+	return lookupBatch(ctx, persons, p.AgifyPerson)
+}
+
+func (p *MorphologyProfiler) GenderizeBatch(ctx context.Context, persons []Person) ([]GenderizeResponse, error) {
+	return lookupBatch(ctx, persons, p.GenderizePerson)
+}
+
+func (p *MorphologyProfiler) NationalizeBatch(ctx context.Context, persons []Person) ([]NationalizeResponse, error) {
+	return lookupBatch(ctx, persons, p.NationalizePerson)
+}
+
+func (p *MorphologyProfiler) ProfileBatch(ctx context.Context, persons []Person) ([]Profile, error) {
+	return profileBatch(ctx, p, persons)
+}
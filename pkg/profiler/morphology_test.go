@@ -0,0 +1,132 @@
+package profiler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMorphologyProfiler_GenderizePerson(t *testing.T) {
+	tests := []struct {
+		name   string
+		person Person
+		want   GenderizeResponse
+	}{
+		{
+			name:   "Patronymic",
+			person: Person{Name: "Sasha", Patronymic: "Sergeevna"},
+			want:   GenderizeResponse{Gender: "female", Probability: patronymicGenderProbability},
+		},
+		{
+			name:   "Patronymic Takes Precedence",
+			person: Person{Name: "Sasha", Surname: "Ivanov", Patronymic: "Sergeevna"},
+			want:   GenderizeResponse{Gender: "female", Probability: patronymicGenderProbability},
+		},
+		{
+			name:   "Surname Without Patronymic",
+			person: Person{Name: "Sasha", Surname: "Ivanova", Patronymic: "Smith"},
+			want:   GenderizeResponse{Gender: "female", Probability: surnameGenderProbability},
+		},
+		{
+			name:   "Latin In",
+			person: Person{Name: "Alexander", Surname: "Pushkin"},
+			want:   GenderizeResponse{Gender: "male", Probability: surnameGenderProbability},
+		},
+		{
+			name:   "Latin Yn",
+			person: Person{Name: "Vladimir", Surname: "Vysotskyn"},
+			want:   GenderizeResponse{Gender: "male", Probability: surnameGenderProbability},
+		},
+		{
+			name:   "Latin Ina",
+			person: Person{Name: "Anna", Surname: "pushkina"},
+			want:   GenderizeResponse{Gender: "female", Probability: surnameGenderProbability},
+		},
+		{
+			name:   "Cyrillic In",
+			person: Person{Name: "Александр", Surname: "Пушкин"},
+			want:   GenderizeResponse{Gender: "male", Probability: surnameGenderProbability},
+		},
+		{
+			name:   "Cyrillic Ina",
+			person: Person{Name: "Анна", Surname: "ПУШКИНА"},
+			want:   GenderizeResponse{Gender: "female", Probability: surnameGenderProbability},
+		},
+		{
+			name:   "Cyrillic Patronymic",
+			person: Person{Name: "Анна", Surname: "Пушкин", Patronymic: "Ивановна"},
+			want:   GenderizeResponse{Gender: "female", Probability: patronymicGenderProbability},
+		},
+		{
+			name:   "Longest Suffix Wins",
+			person: Person{Name: "Maria", Surname: "Dostoevskaya"},
+			want:   GenderizeResponse{Gender: "female", Probability: surnameGenderProbability},
+		},
+		{
+			name:   "Longer Exception Wins Over Ina",
+			person: Person{Name: "Marco", Surname: "Messina"},
+		},
+		{
+			name:   "Longer Exception Wins Over In",
+			person: Person{Name: "Steve", Surname: "Martin"},
+		},
+		{
+			name:   "Suffix Alone",
+			person: Person{Name: "Oleg", Surname: "Ov"},
+		},
+		{
+			name:   "Unknown",
+			person: Person{Name: "John", Surname: "Smith"},
+		},
+		{
+			name:   "Name Only",
+			person: Person{Name: "Dmitriy"},
+		},
+	}
+
+	p := NewMorphologyProfiler()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := p.GenderizePerson(context.Background(), tt.person)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMorphologyProfiler_NationalizePerson(t *testing.T) {
+	tests := []struct {
+		name   string
+		person Person
+		want   string
+	}{
+		{name: "Russian Surname", person: Person{Surname: "Ivanov"}, want: "RU"},
+		{name: "Ukrainian Surname", person: Person{Surname: "Shevchenko", Patronymic: "Grigorievich"}, want: "UA"},
+		{name: "Belarusian Surname", person: Person{Surname: "Раманенка"}, want: "BY"},
+		{name: "Cyrillic Surname", person: Person{Surname: "Ковальчук"}, want: "UA"},
+		{name: "Patronymic Only", person: Person{Surname: "Smith", Patronymic: "Ivanovich"}, want: "RU"},
+		{name: "Unknown", person: Person{Surname: "Messina"}},
+	}
+
+	p := NewMorphologyProfiler()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := p.NationalizePerson(context.Background(), tt.person)
+			assert.NoError(t, err)
+			if tt.want == "" {
+				assert.Empty(t, got.Country)
+				return
+			}
+			if assert.NotEmpty(t, got.Country) {
+				assert.Equal(t, tt.want, got.Country[0].CountryID)
+			}
+		})
+	}
+}
+
+func TestMorphologyProfiler_AgifyPerson(t *testing.T) {
+	got, err := NewMorphologyProfiler().AgifyPerson(context.Background(), Person{Name: "Ivan", Surname: "Ivanov"})
+	assert.NoError(t, err)
+	assert.Equal(t, AgifyResponse{}, got)
+}
@@ -1,21 +1,45 @@
 package profiler
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 )
 
+// MaxBatchSize is the number of names the upstreams accept in a single request.
+const MaxBatchSize = 10
+
+var (
+	// ErrRateLimited is returned when the upstream keeps answering 429.
+	ErrRateLimited = errors.New("profiler: rate limited")
+	// ErrUnavailable is returned when the upstream can't be reached or answers 5xx.
+	ErrUnavailable = errors.New("profiler: upstream unavailable")
+	// ErrUpstreamFailed is returned for answers that won't succeed on retry.
+	ErrUpstreamFailed = errors.New("profiler: upstream failed")
+
+	// errRejected marks an ErrUpstreamFailed the upstream answered with a
+	// 4xx, the upstream is up but won't take the request.
+	errRejected = errors.New("HTTP request failed with status")
+)
+
 type NameProfiler struct {
 	agifyResource       string
 	genderizeResource   string
 	nationalizeResource string
 	client              http.Client
+	retry               RetryPolicy
 }
 
-func NewNameProfiler(agifyResource, genderizeResource, nationalizeResource string, timeout time.Duration) *NameProfiler {
+func NewNameProfiler(agifyResource, genderizeResource, nationalizeResource string, timeout time.Duration,
+	retry RetryPolicy) *NameProfiler {
+	if retry.MaxAttempts < 1 {
+		retry.MaxAttempts = 1
+	}
 	return &NameProfiler{
 		client: http.Client{
 			Timeout: timeout,
@@ -23,109 +47,150 @@ func NewNameProfiler(agifyResource, genderizeResource, nationalizeResource strin
 		agifyResource:       agifyResource,
 		genderizeResource:   genderizeResource,
 		nationalizeResource: nationalizeResource,
+		retry:               retry,
 	}
 }
 
-type AgifyResponse struct {
-	Age int `json:"age"`
+func (p *NameProfiler) AgifyPerson(ctx context.Context, person Person) (AgifyResponse, error) {
+	var agifyResponse AgifyResponse
+	err := p.fetch(ctx, p.agifyResource, url.Values{"name": {person.Name}}, &agifyResponse)
+	return agifyResponse, err
 }
 
-type GenderizeResponse struct {
-	Gender string `json:"gender"`
+func (p *NameProfiler) GenderizePerson(ctx context.Context, person Person) (GenderizeResponse, error) {
+	var genderizeResponse GenderizeResponse
+	err := p.fetch(ctx, p.genderizeResource, url.Values{"name": {person.Name}}, &genderizeResponse)
+	return genderizeResponse, err
 }
 
-type NationalizeResponse struct {
-	Country []Country `json:"country"`
+func (p *NameProfiler) NationalizePerson(ctx context.Context, person Person) (NationalizeResponse, error) {
+	var nationalizeResponse NationalizeResponse
+	err := p.fetch(ctx, p.nationalizeResource, url.Values{"name": {person.Name}}, &nationalizeResponse)
+	return nationalizeResponse, err
 }
 
-type Country struct {
-	CountryID string `json:"country_id"`
+func (p *NameProfiler) AgifyBatch(ctx context.Context, persons []Person) ([]AgifyResponse, error) {
+	return fetchBatch[AgifyResponse](ctx, p, p.agifyResource, persons)
 }
 
-func (p *NameProfiler) AgifyPerson(name string) (int, error) {
-	req, err := http.NewRequest("GET", p.agifyResource, nil)
-	if err != nil {
-		return 0, fmt.Errorf("failed to create new request due to error: %v", err)
-	}
-
-	q := req.URL.Query()
-	q.Add("name", name)
-	req.URL.RawQuery = q.Encode()
-	resp, err := p.client.Do(req)
-	if err != nil {
-		return 0, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("HTTP request failed with status: %d", resp.StatusCode)
-	}
+func (p *NameProfiler) GenderizeBatch(ctx context.Context, persons []Person) ([]GenderizeResponse, error) {
+	return fetchBatch[GenderizeResponse](ctx, p, p.genderizeResource, persons)
+}
 
-	var agifyResponse AgifyResponse
-	decoder := json.NewDecoder(resp.Body)
-	if err := decoder.Decode(&agifyResponse); err != nil {
-		return 0, errors.New("error while decoding")
-	}
+func (p *NameProfiler) NationalizeBatch(ctx context.Context, persons []Person) ([]NationalizeResponse, error) {
+	return fetchBatch[NationalizeResponse](ctx, p, p.nationalizeResource, persons)
+}
 
-	return agifyResponse.Age, nil
+func (p *NameProfiler) ProfileBatch(ctx context.Context, persons []Person) ([]Profile, error) {
+	return profileBatch(ctx, p, persons)
 }
 
-func (p *NameProfiler) GenderizePerson(name string) (string, error) {
-	req, err := http.NewRequest("GET", p.genderizeResource, nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to create new request due to error: %v", err)
+// fetchBatch queries the resource for the distinct first names of persons,
+// at most MaxBatchSize per request, and returns the answers in the order of
+// persons.
+func fetchBatch[T any](ctx context.Context, p *NameProfiler, resource string, persons []Person) ([]T, error) {
+	index := make(map[string]int, len(persons))
+	names := make([]string, 0, len(persons))
+	for _, person := range persons {
+		key := strings.ToLower(person.Name)
+		if _, ok := index[key]; !ok {
+			index[key] = len(names)
+			names = append(names, person.Name)
+		}
 	}
 
-	q := req.URL.Query()
-	q.Add("name", name)
-	req.URL.RawQuery = q.Encode()
-	resp, err := p.client.Do(req)
-	if err != nil {
-		return "", err
+	responses := make([]T, 0, len(names))
+	for start := 0; start < len(names); start += MaxBatchSize {
+		end := start + MaxBatchSize
+		if end > len(names) {
+			end = len(names)
+		}
+		chunk := names[start:end]
+
+		var page []T
+		if err := p.fetch(ctx, resource, url.Values{"name[]": chunk}, &page); err != nil {
+			return nil, err
+		}
+		if len(page) != len(chunk) {
+			return nil, fmt.Errorf("%w: got %d answers for %d names", ErrUpstreamFailed, len(page), len(chunk))
+		}
+		responses = append(responses, page...)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("HTTP request failed with status: %d", resp.StatusCode)
+	answers := make([]T, len(persons))
+	for i, person := range persons {
+		answers[i] = responses[index[strings.ToLower(person.Name)]]
 	}
+	return answers, nil
+}
 
-	var genderizeResponse GenderizeResponse
-	decoder := json.NewDecoder(resp.Body)
-	if err := decoder.Decode(&genderizeResponse); err != nil {
-		return "", errors.New("error while decoding")
+// fetch queries the resource with the query and decodes the answer into v,
+// retrying rate limited and unavailable upstreams according to the policy.
+func (p *NameProfiler) fetch(ctx context.Context, resource string, query url.Values, v interface{}) error {
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := p.fetchOnce(ctx, resource, query, v)
+		if err == nil || !retryable(err) || attempt+1 >= p.retry.MaxAttempts {
+			return err
+		}
+
+		delay := p.retry.backoff(attempt)
+		if retryAfter > 0 {
+			if retryAfter > p.retry.MaxDelay {
+				return err
+			}
+			delay = retryAfter
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
 	}
-
-	return genderizeResponse.Gender, nil
 }
 
-func (p *NameProfiler) NationalizePerson(name string) (string, error) {
-	req, err := http.NewRequest("GET", p.nationalizeResource, nil)
+// fetchOnce makes a single request, it also returns the delay the upstream asked for.
+func (p *NameProfiler) fetchOnce(ctx context.Context, resource string, query url.Values, v interface{}) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", resource, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create new request due to error: %v", err)
+		return 0, fmt.Errorf("failed to create new request due to error: %v", err)
 	}
 
 	q := req.URL.Query()
-	q.Add("name", name)
+	for key, values := range query {
+		q[key] = append(q[key], values...)
+	}
 	req.URL.RawQuery = q.Encode()
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return "", err
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		return 0, fmt.Errorf("%w: %v", ErrUnavailable, err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("HTTP request failed with status: %d", resp.StatusCode)
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return parseRetryAfter(resp.Header.Get("Retry-After")),
+			fmt.Errorf("%w: HTTP request failed with status: %d", ErrRateLimited, resp.StatusCode)
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return parseRetryAfter(resp.Header.Get("Retry-After")),
+			fmt.Errorf("%w: HTTP request failed with status: %d", ErrUnavailable, resp.StatusCode)
+	case resp.StatusCode != http.StatusOK:
+		return 0, fmt.Errorf("%w: %w: %d", ErrUpstreamFailed, errRejected, resp.StatusCode)
 	}
 
-	var nationalizeResponse NationalizeResponse
 	decoder := json.NewDecoder(resp.Body)
-	if err := decoder.Decode(&nationalizeResponse); err != nil {
-		return "", errors.New("error while decoding")
+	if err := decoder.Decode(v); err != nil {
+		return 0, fmt.Errorf("%w: error while decoding", ErrUpstreamFailed)
 	}
 
-	if len(nationalizeResponse.Country) == 0 {
-		return "", nil
-	}
+	return 0, nil
+}
 
-	return nationalizeResponse.Country[0].CountryID, nil
+func retryable(err error) bool {
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrUnavailable)
 }
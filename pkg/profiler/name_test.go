@@ -0,0 +1,241 @@
+package profiler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestUpstream answers every request with the statuses in turn, the last
+// one is repeated. It also counts the requests.
+func newTestUpstream(t *testing.T, retryAfter string, statuses ...int) (*httptest.Server, *int32) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&requests, 1))
+		status := statuses[len(statuses)-1]
+		if n <= len(statuses) {
+			status = statuses[n-1]
+		}
+		if retryAfter != "" {
+			w.Header().Set("Retry-After", retryAfter)
+		}
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			w.Write([]byte(`{"age":42,"count":1200}`)) //nolint:errcheck
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestNameProfiler_fetch(t *testing.T) {
+	retry := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+	tests := []struct {
+		name         string
+		statuses     []int
+		retryAfter   string
+		want         AgifyResponse
+		wantErr      error
+		wantRequests int32
+	}{
+		{
+			name:         "OK",
+			statuses:     []int{http.StatusOK},
+			want:         AgifyResponse{Age: 42, Count: 1200},
+			wantRequests: 1,
+		},
+		{
+			name:         "Rate Limited Then OK",
+			statuses:     []int{http.StatusTooManyRequests, http.StatusOK},
+			want:         AgifyResponse{Age: 42, Count: 1200},
+			wantRequests: 2,
+		},
+		{
+			name:         "Rate Limited",
+			statuses:     []int{http.StatusTooManyRequests},
+			wantErr:      ErrRateLimited,
+			wantRequests: 3,
+		},
+		{
+			name:         "Unavailable",
+			statuses:     []int{http.StatusBadGateway},
+			wantErr:      ErrUnavailable,
+			wantRequests: 3,
+		},
+		{
+			name:         "Client Error Not Retried",
+			statuses:     []int{http.StatusBadRequest},
+			wantErr:      ErrUpstreamFailed,
+			wantRequests: 1,
+		},
+		{
+			name:         "Retry After Above Max Delay",
+			statuses:     []int{http.StatusTooManyRequests},
+			retryAfter:   "60",
+			wantErr:      ErrRateLimited,
+			wantRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, requests := newTestUpstream(t, tt.retryAfter, tt.statuses...)
+			p := NewNameProfiler(srv.URL, srv.URL, srv.URL, time.Second, retry)
+
+			got, err := p.AgifyPerson(context.Background(), Person{Name: "Dmitriy"})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.Equal(t, tt.wantRequests, atomic.LoadInt32(requests))
+		})
+	}
+}
+
+func TestNameProfiler_fetch_RetryAfter(t *testing.T) {
+	retry := RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Second}
+
+	tests := []struct {
+		name       string
+		retryAfter func() string
+		minWait    time.Duration
+	}{
+		{name: "Seconds", retryAfter: func() string { return "1" }, minWait: time.Second},
+		{
+			name: "Date",
+			// an HTTP date is cut to the second, so it is 1 to 2 seconds away
+			retryAfter: func() string { return time.Now().Add(2 * time.Second).UTC().Format(http.TimeFormat) },
+			minWait:    900 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, requests := newTestUpstream(t, tt.retryAfter(), http.StatusServiceUnavailable, http.StatusOK)
+			p := NewNameProfiler(srv.URL, srv.URL, srv.URL, time.Second, retry)
+
+			start := time.Now()
+			_, err := p.AgifyPerson(context.Background(), Person{Name: "Dmitriy"})
+			assert.NoError(t, err)
+			assert.GreaterOrEqual(t, time.Since(start), tt.minWait)
+			assert.Equal(t, int32(2), atomic.LoadInt32(requests))
+		})
+	}
+}
+
+func TestNameProfiler_fetch_CancelledWhileWaiting(t *testing.T) {
+	srv, requests := newTestUpstream(t, "5", http.StatusServiceUnavailable)
+	p := NewNameProfiler(srv.URL, srv.URL, srv.URL, time.Second,
+		RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Second})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := p.AgifyPerson(ctx, Person{Name: "Dmitriy"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, int32(1), atomic.LoadInt32(requests))
+}
+
+// newBatchUpstream answers batch age lookups with the ages of the names, the
+// last answer is left out if short is set. It records the names of every request.
+func newBatchUpstream(t *testing.T, ages map[string]int, short bool) (*httptest.Server, *[][]string) {
+	var (
+		mu     sync.Mutex
+		chunks [][]string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		names := r.URL.Query()["name[]"]
+		mu.Lock()
+		chunks = append(chunks, names)
+		mu.Unlock()
+
+		resp := make([]AgifyResponse, 0, len(names))
+		for _, name := range names {
+			resp = append(resp, AgifyResponse{Age: ages[strings.ToLower(name)], Count: 1})
+		}
+		if short {
+			resp = resp[:len(resp)-1]
+		}
+		json.NewEncoder(w).Encode(resp) //nolint:errcheck
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &chunks
+}
+
+func TestNameProfiler_AgifyBatch(t *testing.T) {
+	ages := map[string]int{"anna": 30, "ivan": 55}
+	var many []Person
+	var manyNames []string
+	var manyAnswers []AgifyResponse
+	for i := 0; i < 23; i++ {
+		name := fmt.Sprintf("name%02d", i)
+		ages[name] = i + 1
+		many = append(many, Person{Name: name})
+		manyNames = append(manyNames, name)
+		manyAnswers = append(manyAnswers, AgifyResponse{Age: i + 1, Count: 1})
+	}
+
+	tests := []struct {
+		name       string
+		persons    []Person
+		short      bool
+		want       []AgifyResponse
+		wantChunks [][]string
+		wantErr    error
+	}{
+		{
+			name:       "Chunked By Max Batch Size",
+			persons:    many,
+			want:       manyAnswers,
+			wantChunks: [][]string{manyNames[:10], manyNames[10:20], manyNames[20:]},
+		},
+		{
+			name:       "Duplicate Names Asked Once",
+			persons:    []Person{{Name: "Anna", Surname: "Ivanova"}, {Name: "Ivan"}, {Name: "ANNA"}, {Name: "anna"}},
+			want:       []AgifyResponse{{Age: 30, Count: 1}, {Age: 55, Count: 1}, {Age: 30, Count: 1}, {Age: 30, Count: 1}},
+			wantChunks: [][]string{{"Anna", "Ivan"}},
+		},
+		{
+			name:       "Answer Missing",
+			persons:    []Person{{Name: "Anna"}, {Name: "Ivan"}},
+			short:      true,
+			wantChunks: [][]string{{"Anna", "Ivan"}},
+			wantErr:    ErrUpstreamFailed,
+		},
+		{
+			name:    "No Persons",
+			persons: []Person{},
+			want:    []AgifyResponse{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, chunks := newBatchUpstream(t, ages, tt.short)
+			p := NewNameProfiler(srv.URL, srv.URL, srv.URL, time.Second, RetryPolicy{MaxAttempts: 1})
+
+			got, err := p.AgifyBatch(context.Background(), tt.persons)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.Equal(t, tt.wantChunks, *chunks)
+		})
+	}
+}
@@ -1,7 +1,163 @@
 package profiler
 
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
 type Profiler interface {
-	AgifyPerson(name string) (int, error)
-	GenderizePerson(name string) (string, error)
-	NationalizePerson(name string) (string, error)
+	AgifyPerson(ctx context.Context, person Person) (AgifyResponse, error)
+	GenderizePerson(ctx context.Context, person Person) (GenderizeResponse, error)
+	NationalizePerson(ctx context.Context, person Person) (NationalizeResponse, error)
+
+	// The batch lookups return one answer per person, in the order of persons.
+	AgifyBatch(ctx context.Context, persons []Person) ([]AgifyResponse, error)
+	GenderizeBatch(ctx context.Context, persons []Person) ([]GenderizeResponse, error)
+	NationalizeBatch(ctx context.Context, persons []Person) ([]NationalizeResponse, error)
+
+	// ProfileBatch runs all lookups for persons with as few requests as possible.
+	ProfileBatch(ctx context.Context, persons []Person) ([]Profile, error)
+}
+
+// Person is what a lookup may look at. The public APIs only know first names,
+// rules may use the surname and patronymic as well.
+type Person struct {
+	Name       string
+	Surname    string
+	Patronymic string
+}
+
+// key tells persons apart regardless of case.
+func (p Person) key() string {
+	return strings.ToLower(p.Name + "\x00" + p.Surname + "\x00" + p.Patronymic)
+}
+
+// AgifyResponse is the age guessed for a name. Count is the number of
+// samples behind the guess, zero if the name is unknown.
+type AgifyResponse struct {
+	Age   int `json:"age"`
+	Count int `json:"count"`
+	// Source names the provider of the answer, it is set by ChainProfiler.
+	Source string `json:"source,omitempty"`
+}
+
+// GenderizeResponse is the gender guessed for a name and how likely it is.
+type GenderizeResponse struct {
+	Gender      string  `json:"gender"`
+	Probability float64 `json:"probability"`
+	Count       int     `json:"count"`
+	Source      string  `json:"source,omitempty"`
+}
+
+// NationalizeResponse lists the likely countries of a name, the most likely first.
+type NationalizeResponse struct {
+	Country []Country `json:"country"`
+	Count   int       `json:"count"`
+	Source  string    `json:"source,omitempty"`
+}
+
+type Country struct {
+	CountryID   string  `json:"country_id"`
+	Probability float64 `json:"probability"`
+}
+
+// The known methods report whether the upstream knows anything about the
+// name, the confident methods whether the guess is at least minProbability
+// likely. Ages come without a probability, a known age is confident.
+
+func (r AgifyResponse) known() bool { return r.Age != 0 }
+
+func (r AgifyResponse) confident(_ float64) bool { return r.known() }
+
+func (r GenderizeResponse) known() bool { return r.Gender != "" }
+
+func (r GenderizeResponse) confident(minProbability float64) bool {
+	return r.known() && r.Probability >= minProbability
+}
+
+func (r NationalizeResponse) known() bool { return len(r.Country) != 0 }
+
+func (r NationalizeResponse) confident(minProbability float64) bool {
+	return r.known() && r.Country[0].Probability >= minProbability
+}
+
+// Profile is everything the upstreams tell about a name.
+type Profile struct {
+	Age         AgifyResponse
+	Gender      GenderizeResponse
+	Nationality NationalizeResponse
+}
+
+// profileBatch asks every batch lookup of p about the distinct persons side by
+// side and spreads the answers back over persons. The first failed lookup
+// cancels the others.
+func profileBatch(ctx context.Context, p Profiler, persons []Person) ([]Profile, error) {
+	index := make(map[string]int, len(persons))
+	distinct := make([]Person, 0, len(persons))
+	for _, person := range persons {
+		key := person.key()
+		if _, ok := index[key]; !ok {
+			index[key] = len(distinct)
+			distinct = append(distinct, person)
+		}
+	}
+	if len(distinct) == 0 {
+		return []Profile{}, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	var (
+		ages          []AgifyResponse
+		genders       []GenderizeResponse
+		nationalities []NationalizeResponse
+	)
+	wg.Add(3) //nolint:gomnd
+	go func() {
+		defer wg.Done()
+		var err error
+		if ages, err = p.AgifyBatch(ctx, distinct); err != nil {
+			fail(err)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		var err error
+		if genders, err = p.GenderizeBatch(ctx, distinct); err != nil {
+			fail(err)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		var err error
+		if nationalities, err = p.NationalizeBatch(ctx, distinct); err != nil {
+			fail(err)
+		}
+	}()
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	profiles := make([]Profile, len(persons))
+	for i, person := range persons {
+		j := index[person.key()]
+		profiles[i] = Profile{Age: ages[j], Gender: genders[j], Nationality: nationalities[j]}
+	}
+	return profiles, nil
 }
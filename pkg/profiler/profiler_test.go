@@ -0,0 +1,101 @@
+package profiler
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// lookupProfiler answers batch lookups from its maps and records the persons
+// it is asked about. A failing lookup waits for the others to be cancelled.
+type lookupProfiler struct {
+	unknownProfiler
+	ages        map[string]int
+	genders     map[string]string
+	failGenders error
+
+	mu    sync.Mutex
+	asked [][]Person
+}
+
+func (p *lookupProfiler) record(persons []Person) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.asked = append(p.asked, persons)
+}
+
+func (p *lookupProfiler) AgifyBatch(ctx context.Context, persons []Person) ([]AgifyResponse, error) {
+	p.record(persons)
+	if p.failGenders != nil {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	resp := make([]AgifyResponse, len(persons))
+	for i, person := range persons {
+		resp[i] = AgifyResponse{Age: p.ages[strings.ToLower(person.Name)]}
+	}
+	return resp, nil
+}
+
+func (p *lookupProfiler) GenderizeBatch(_ context.Context, persons []Person) ([]GenderizeResponse, error) {
+	p.record(persons)
+	if p.failGenders != nil {
+		return nil, p.failGenders
+	}
+	resp := make([]GenderizeResponse, len(persons))
+	for i, person := range persons {
+		resp[i] = GenderizeResponse{Gender: p.genders[strings.ToLower(person.Name)]}
+	}
+	return resp, nil
+}
+
+func (p *lookupProfiler) NationalizeBatch(_ context.Context, persons []Person) ([]NationalizeResponse, error) {
+	p.record(persons)
+	return make([]NationalizeResponse, len(persons)), nil
+}
+
+func TestProfileBatch(t *testing.T) {
+	p := &lookupProfiler{
+		ages:    map[string]int{"anna": 30, "ivan": 55},
+		genders: map[string]string{"anna": "female", "ivan": "male"},
+	}
+	persons := []Person{
+		{Name: "Ivan", Surname: "Ivanov"},
+		{Name: "Anna"},
+		{Name: "IVAN", Surname: "ivanov"},
+		{Name: "Ivan", Surname: "Petrov"},
+	}
+
+	got, err := profileBatch(context.Background(), p, persons)
+	assert.NoError(t, err)
+	assert.Equal(t, []Profile{
+		{Age: AgifyResponse{Age: 55}, Gender: GenderizeResponse{Gender: "male"}, Nationality: NationalizeResponse{}},
+		{Age: AgifyResponse{Age: 30}, Gender: GenderizeResponse{Gender: "female"}, Nationality: NationalizeResponse{}},
+		{Age: AgifyResponse{Age: 55}, Gender: GenderizeResponse{Gender: "male"}, Nationality: NationalizeResponse{}},
+		{Age: AgifyResponse{Age: 55}, Gender: GenderizeResponse{Gender: "male"}, Nationality: NationalizeResponse{}},
+	}, got)
+
+	// every lookup is asked once about the distinct persons
+	distinct := []Person{{Name: "Ivan", Surname: "Ivanov"}, {Name: "Anna"}, {Name: "Ivan", Surname: "Petrov"}}
+	assert.Equal(t, [][]Person{distinct, distinct, distinct}, p.asked)
+}
+
+func TestProfileBatch_Empty(t *testing.T) {
+	p := &lookupProfiler{}
+
+	got, err := profileBatch(context.Background(), p, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []Profile{}, got)
+	assert.Empty(t, p.asked)
+}
+
+func TestProfileBatch_LookupFails(t *testing.T) {
+	p := &lookupProfiler{failGenders: ErrUnavailable}
+
+	got, err := profileBatch(context.Background(), p, []Person{{Name: "Anna"}})
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.Nil(t, got)
+}
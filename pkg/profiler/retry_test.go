@@ -0,0 +1,58 @@
+package profiler
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+
+	tests := []struct {
+		name    string
+		attempt int
+		max     time.Duration
+	}{
+		{name: "First Retry", attempt: 0, max: 10 * time.Millisecond},
+		{name: "Doubled", attempt: 2, max: 40 * time.Millisecond},
+		{name: "Capped", attempt: 3, max: 50 * time.Millisecond},
+		{name: "Overflow Capped", attempt: 70, max: 50 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				delay := policy.backoff(tt.attempt)
+				assert.Greater(t, delay, time.Duration(0))
+				assert.LessOrEqual(t, delay, tt.max)
+			}
+		})
+	}
+
+	assert.Equal(t, time.Duration(0), RetryPolicy{}.backoff(1))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+		delta time.Duration
+	}{
+		{name: "Seconds", value: "3", want: 3 * time.Second},
+		{name: "Date", value: time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), want: time.Minute, delta: 2 * time.Second},
+		{name: "Past Date", value: time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)},
+		{name: "Zero Seconds", value: "0"},
+		{name: "Garbage", value: "soon"},
+		{name: "Empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, float64(tt.want), float64(parseRetryAfter(tt.value)), float64(tt.delta))
+		})
+	}
+}
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS persons_patronymic_trgm_idx;
+DROP INDEX IF EXISTS persons_surname_trgm_idx;
+DROP INDEX IF EXISTS persons_name_trgm_idx;
+
+DROP EXTENSION IF EXISTS pg_trgm;
\ No newline at end of file
@@ -0,0 +1,5 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX persons_name_trgm_idx ON persons USING gin (name gin_trgm_ops);
+CREATE INDEX persons_surname_trgm_idx ON persons USING gin (surname gin_trgm_ops);
+CREATE INDEX persons_patronymic_trgm_idx ON persons USING gin (patronymic gin_trgm_ops);
\ No newline at end of file
@@ -0,0 +1,2 @@
+CREATE INDEX persons_full_name_trgm_idx ON persons
+    USING gin ((name || ' ' || surname || ' ' || coalesce(patronymic, '')) gin_trgm_ops);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE persons DROP COLUMN IF EXISTS enrichment;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE persons ADD COLUMN enrichment jsonb;
\ No newline at end of file
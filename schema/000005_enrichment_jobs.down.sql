@@ -0,0 +1,3 @@
+ALTER TABLE persons DROP COLUMN IF EXISTS enrichment_status;
+
+DROP TABLE IF EXISTS enrichment_jobs;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE enrichment_jobs
+(
+    person_id int not null unique references persons (id) on delete cascade,
+    attempts int not null default 0,
+    run_at timestamptz default now(),
+    last_error text
+);
+
+CREATE INDEX enrichment_jobs_run_at_idx ON enrichment_jobs (run_at) WHERE run_at IS NOT NULL;
+
+ALTER TABLE persons ADD COLUMN enrichment_status varchar(16) not null default 'complete';
\ No newline at end of file
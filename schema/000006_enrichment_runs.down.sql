@@ -0,0 +1,3 @@
+ALTER TABLE enrichment_jobs DROP COLUMN IF EXISTS run_id;
+
+DROP TABLE IF EXISTS enrichment_runs;
\ No newline at end of file
@@ -0,0 +1,12 @@
+CREATE TABLE enrichment_runs
+(
+    id serial primary key,
+    filters text not null default '',
+    rate double precision not null,
+    total int not null default 0,
+    created_at timestamptz not null default now()
+);
+
+ALTER TABLE enrichment_jobs ADD COLUMN run_id int references enrichment_runs (id) on delete set null;
+
+CREATE INDEX enrichment_jobs_run_id_idx ON enrichment_jobs (run_id);
\ No newline at end of file
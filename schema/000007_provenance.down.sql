@@ -0,0 +1,4 @@
+ALTER TABLE enrichment_jobs DROP COLUMN IF EXISTS force;
+ALTER TABLE enrichment_runs DROP COLUMN IF EXISTS force;
+
+ALTER TABLE persons DROP COLUMN IF EXISTS provenance;
\ No newline at end of file
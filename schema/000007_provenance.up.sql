@@ -0,0 +1,4 @@
+ALTER TABLE persons ADD COLUMN provenance jsonb;
+
+ALTER TABLE enrichment_runs ADD COLUMN force boolean not null default false;
+ALTER TABLE enrichment_jobs ADD COLUMN force boolean not null default false;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE enrichment_jobs ADD COLUMN leased_until timestamptz;
\ No newline at end of file